@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/repository"
+	"github.com/nvbn/termonizer/internal/storage"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+const backupUsage = `usage: termonizer backup [list]
+       termonizer restore <snapshot>`
+
+func newBackups(cfg *config.Config, store storage.Storage) (*repository.Backups, error) {
+	sqlite, ok := store.(*storage.SQLite)
+	if !ok {
+		return nil, errors.New("backups are only supported by the sqlite storage, keep goals files in git instead")
+	}
+
+	if cfg.BackupDir == "" {
+		return nil, errors.New("backup_dir isn't set")
+	}
+
+	return repository.NewBackups(time.Now, os.ExpandEnv(cfg.BackupDir), repository.Retention{
+		Daily:   cfg.BackupDaily,
+		Weekly:  cfg.BackupWeekly,
+		Monthly: cfg.BackupMonthly,
+	}, sqlite), nil
+}
+
+// backupCommand snapshots the database or lists snapshots
+func backupCommand(ctx context.Context, out io.Writer, backups *repository.Backups, args []string) error {
+	if len(args) == 0 {
+		snapshot, err := backups.Create(ctx)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "created %s\n", snapshot.Path)
+		return nil
+	}
+
+	if len(args) > 1 || args[0] != "list" {
+		return errors.New(backupUsage)
+	}
+
+	snapshots, err := backups.List()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "SNAPSHOT\tCREATED")
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%s\t%s\n", snapshot.Name, snapshot.Created.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+func restoreCommand(ctx context.Context, out io.Writer, backups *repository.Backups, args []string) error {
+	if len(args) != 1 {
+		return errors.New(backupUsage)
+	}
+
+	snapshot, err := backups.Restore(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "restored %s, the previous state is snapshotted too\n", snapshot.Name)
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/repository"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// configCommand prints the effective config with the source of every value and validates it
+func configCommand(out io.Writer, settings []model.Setting) error {
+	cfg, err := loadConfig(settings)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	// keys like keymap.focus_left are printed in [keymap] table
+	prefixToTable := map[string]string{"keymap.": "keymap", "template.": "templates"}
+	tableToKeys := make(map[string][]string)
+	for _, key := range cfg.Keys() {
+		if prefix, ok := tablePrefix(prefixToTable, key); ok {
+			tableToKeys[prefix] = append(tableToKeys[prefix], key)
+			continue
+		}
+
+		fmt.Fprintf(out, "%s = %s # %s\n", key, formatValue(key, cfg.Value(key)), cfg.Source(key))
+	}
+
+	for _, prefix := range slices.Sorted(maps.Keys(tableToKeys)) {
+		fmt.Fprintf(out, "\n[%s]\n", prefixToTable[prefix])
+		for _, key := range tableToKeys[prefix] {
+			fmt.Fprintf(out, "%s = %s # %s\n", strings.TrimPrefix(key, prefix), formatValue(key, cfg.Value(key)), cfg.Source(key))
+		}
+	}
+
+	for _, period := range slices.Sorted(maps.Keys(cfg.Templates)) {
+		name := strings.ToLower(model.PeriodName(period))
+		if cfg.Value("template."+name) == nil {
+			fmt.Fprintf(out, "# %s template is read from %s.md in templates_dir\n", name, name)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Themes)) {
+		fmt.Fprintf(out, "\n[themes.%s] # file\n", name)
+		for _, key := range slices.Sorted(maps.Keys(cfg.Themes[name])) {
+			fmt.Fprintf(out, "%s = %s\n", key, strconv.Quote(cfg.Themes[name][key]))
+		}
+	}
+
+	if _, err := uiOptions(cfg); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	if _, err := repository.NewTemplates(cfg.Templates); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	return nil
+}
+
+func tablePrefix(prefixToTable map[string]string, key string) (string, bool) {
+	for prefix := range prefixToTable {
+		if strings.HasPrefix(key, prefix) {
+			return prefix, true
+		}
+	}
+
+	return "", false
+}
+
+func formatValue(key string, value []string) string {
+	// the output could be shared when asking for help
+	if key == config.KeyAPIToken && slices.ContainsFunc(value, func(v string) bool { return v != "" }) {
+		return strconv.Quote("<hidden>")
+	}
+
+	quoted := make([]string, len(value))
+	for n, v := range value {
+		quoted[n] = strconv.Quote(v)
+	}
+
+	if key == config.KeyPeriods || strings.HasPrefix(key, "keymap.") {
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/reminders"
+	"github.com/nvbn/termonizer/internal/repository"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// daemonCommand fires reminders of day goals until interrupted, for when the app isn't running
+func daemonCommand(ctx context.Context, out io.Writer, goals *repository.Goals, notifyCommand []string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	notify := reminders.Command(notifyCommand)
+	scheduler := reminders.NewScheduler(time.Now, goals, func(reminder model.Reminder) {
+		fmt.Fprintf(out, "%s %s\n", reminder.At.Format("15:04"), reminder.Text)
+		if err := notify(reminder); err != nil {
+			fmt.Fprintln(out, err)
+		}
+	})
+
+	fmt.Fprintln(out, "waiting for reminders")
+	scheduler.Run(ctx, reminders.CheckInterval)
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/storage"
+	"golang.org/x/term"
+	"io"
+	"os"
+)
+
+const encryptionUsage = `usage: termonizer encryption enable
+       termonizer encryption rotate
+       termonizer encryption disable`
+
+// readPassphrase reads TERMONIZER_PASSPHRASE or prompts for the passphrase without echo
+func readPassphrase(prompt string) (string, error) {
+	if passphrase, ok := os.LookupEnv("TERMONIZER_PASSPHRASE"); ok {
+		return passphrase, nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return string(passphrase), nil
+}
+
+func readNewPassphrase() (string, error) {
+	passphrase, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase == "" {
+		return "", errors.New("passphrase can't be empty")
+	}
+
+	// the env variable is used in scripts, there's no one to confirm it
+	if _, ok := os.LookupEnv("TERMONIZER_PASSPHRASE"); ok {
+		return passphrase, nil
+	}
+
+	confirmation, err := readPassphrase("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if confirmation != passphrase {
+		return "", errors.New("passphrases don't match")
+	}
+
+	return passphrase, nil
+}
+
+// unlock asks for the passphrase when the database is encrypted
+func unlock(ctx context.Context, store storage.Storage) error {
+	sqlite, ok := store.(*storage.SQLite)
+	if !ok {
+		return nil
+	}
+
+	encrypted, err := sqlite.IsEncrypted(ctx)
+	if err != nil || !encrypted {
+		return err
+	}
+
+	passphrase, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return err
+	}
+
+	return sqlite.Unlock(ctx, passphrase)
+}
+
+func encryptionCommand(ctx context.Context, out io.Writer, store storage.Storage, args []string) error {
+	if len(args) != 1 {
+		return errors.New(encryptionUsage)
+	}
+
+	sqlite, ok := store.(*storage.SQLite)
+	if !ok {
+		return errors.New("encryption is only supported by the sqlite storage")
+	}
+
+	encrypted, err := sqlite.IsEncrypted(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "enable", "rotate":
+		if args[0] == "enable" && encrypted {
+			return errors.New("the database is already encrypted, use rotate to change the passphrase")
+		} else if args[0] == "rotate" && !encrypted {
+			return errors.New("the database isn't encrypted, use enable")
+		}
+
+		passphrase, err := readNewPassphrase()
+		if err != nil {
+			return err
+		}
+
+		if err := sqlite.ChangePassphrase(ctx, passphrase); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, "goals are encrypted, old backups keep the previous passphrase")
+		return nil
+	case "disable":
+		if !encrypted {
+			return errors.New("the database isn't encrypted")
+		}
+
+		if err := sqlite.ChangePassphrase(ctx, ""); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, "goals are decrypted")
+		return nil
+	default:
+		return errors.New(encryptionUsage)
+	}
+}
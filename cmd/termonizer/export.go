@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/org"
+	"github.com/nvbn/termonizer/internal/repository"
+	"github.com/nvbn/termonizer/internal/todotxt"
+	"io"
+	"os"
+)
+
+const exportUsage = `usage: termonizer export org [--output file.org]
+       termonizer export todotxt [--output todo.txt]`
+
+func exportCommand(ctx context.Context, out io.Writer, goals *repository.Goals, args []string) error {
+	if len(args) == 0 {
+		return errors.New(exportUsage)
+	}
+
+	var write func(io.Writer, []model.Goal) error
+	switch args[0] {
+	case "org":
+		write = org.Write
+	case "todotxt":
+		write = todotxt.Write
+	default:
+		return errors.New(exportUsage)
+	}
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := flags.String("output", "", "path to the file, stdout when empty")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	all, err := goals.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return write(out, all)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *output, err)
+	}
+
+	if err := write(f, all); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/ical"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/repository"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// icsCommand exports goals of the periods to the file or to the output
+func icsCommand(ctx context.Context, out io.Writer, goals *repository.Goals, args []string) error {
+	flags := flag.NewFlagSet("ics", flag.ContinueOnError)
+	periodsFlag := flags.String("periods", "year,quarter,week,day", "comma separated exported periods")
+	output := flags.String("output", "", "path to the .ics file, stdout when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	periods := make([]model.Period, 0)
+	for _, name := range strings.Split(*periodsFlag, ",") {
+		period, err := model.ParsePeriod(strings.TrimSpace(name))
+		if err != nil {
+			return err
+		}
+		periods = append(periods, period)
+	}
+
+	all, err := goals.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	exported := slices.DeleteFunc(all, func(goal model.Goal) bool { return !slices.Contains(periods, goal.Period) })
+
+	if *output == "" {
+		return ical.Write(out, exported, time.Now())
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *output, err)
+	}
+
+	if err := ical.Write(f, exported, time.Now()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/org"
+	"github.com/nvbn/termonizer/internal/repository"
+	"github.com/nvbn/termonizer/internal/todotxt"
+	"io"
+	"os"
+	"time"
+)
+
+const importUsage = `usage: termonizer import org <file.org>
+       termonizer import todotxt <todo.txt>`
+
+func importCommand(ctx context.Context, out io.Writer, goals *repository.Goals, args []string) error {
+	if len(args) != 2 {
+		return errors.New(importUsage)
+	}
+
+	f, err := os.Open(args[1])
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", args[1], err)
+	}
+	defer f.Close()
+
+	var imported []model.Goal
+	// todo.txt has only checklist items, so they're merged into stored goals instead of replacing them
+	importGoals := goals.Import
+	switch args[0] {
+	case "org":
+		imported, err = org.Read(f)
+	case "todotxt":
+		imported, err = todotxt.Read(f, time.Now())
+		importGoals = goals.ImportChecklists
+	default:
+		return errors.New(importUsage)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", args[1], err)
+	}
+
+	changed, err := importGoals(ctx, imported)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "imported %d goals, %d changed\n", len(imported), changed)
+	return nil
+}
@@ -4,46 +4,103 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/model"
 	"github.com/nvbn/termonizer/internal/repository"
 	"github.com/nvbn/termonizer/internal/storage"
+	"github.com/nvbn/termonizer/internal/theme"
 	"github.com/nvbn/termonizer/internal/ui"
+	"github.com/nvbn/termonizer/internal/utils"
 	"golang.design/x/clipboard"
 	"io"
 	"log"
 	"os"
+	"slices"
 	"time"
 )
 
-var dbPath = flag.String("db", "${HOME}/.termonizer.db", "path to the database")
+var defaults = config.Defaults().Values
+
+var configPath = flag.String("config", utils.ConfigPath("config.toml"), "path to the config file, also TERMONIZER_CONFIG")
 var debug = flag.String("debug", "", "debug output path")
+var themesPath = flag.String("themes", utils.ConfigPath("themes.toml"), "path to the file with user-defined themes, themes from the config file take precedence")
+
+// flags override values from env, the config file and settings only when passed explicitly,
+// values are read with config.FromFlags
+var _ = flag.String("db", defaults[config.KeyDB][0], "path to the database or dir:///path to keep goals in markdown files")
+var _ = flag.String("theme", defaults[config.KeyTheme][0], "color theme: dark, light, high-contrast, solarized or user-defined")
+var _ = flag.String("periods", "year,quarter,week,day", "comma separated enabled periods")
+var _ = flag.String("week-start", defaults[config.KeyWeekStart][0], "first day of the week")
+var _ = flag.String("autosave", defaults[config.KeyAutosave][0], "autosave policy: immediate, delayed or blur")
+var _ = flag.String("autosave-delay", defaults[config.KeyAutosaveDelay][0], "delay for the delayed autosave policy")
+var _ = flag.String("editing-mode", defaults[config.KeyEditingMode][0], "editing mode: default or vim")
 
-var hotkeysDoc = `
-Esc Esc - exit
-
-Navigation:
-  ⌥↑	future/up goal
-  ⇧⌥↑	current/first goal
-  ⌥↓	past/down goal
-  ⇧⌥←	longer/left period
-  ⇧⌥→	shorter/right period
-
-Zooming:
-  ⌥+	zoom in / decrease the amount of visible goals
-  ⌥-	zoom out / increase the amount of visible goals
-
-Text editing:
-  ⌃C	copy
-  ⌃X	cut
-  ⌃V	paste
-  ⌃A	select all
-  Esc	remove selection
+var commandsDoc = `
+Commands:
+  config	print and validate the effective config
+  recurring	list, add or remove items added to new goals, see "termonizer recurring"
+  trash		list, restore or purge deleted goals, see "termonizer trash"
+  backup	snapshot the database now, "termonizer backup list" to list snapshots
+  restore	restore the database from a snapshot, the current state is snapshotted first
+  encryption	enable, rotate or disable encryption of goals with a passphrase
+  search	find goals containing the text, works with encrypted goals
+  sync		commit goals stored in files to git and sync them with sync_remote
+  merge		merge goals with a copy of the database from another machine, both are updated
+  ics		export goals to an iCalendar file, "termonizer ics --periods week,day --output plan.ics"
+  import	import goals from org-mode or todo.txt, "termonizer import org plan.org"
+  export	export goals to org-mode or todo.txt, "termonizer export todotxt --output todo.txt"
+  serve		serve the http api, "termonizer serve --listen 127.0.0.1:8421"
+  daemon	notify about timed items of day goals like "* 14:00 standup" without the app running
 `
 
+func loadConfig(settings []model.Setting) (*config.Config, error) {
+	path := *configPath
+	if fromEnv, ok := os.LookupEnv("TERMONIZER_CONFIG"); ok && !isFlagPassed("config") {
+		path = fromEnv
+	}
+
+	file, err := config.FromFile(os.ExpandEnv(path))
+	if err != nil {
+		return nil, err
+	}
+
+	themes, err := config.ThemesFromFile(os.ExpandEnv(*themesPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Resolve(
+		config.Defaults(),
+		config.FromSettings(settings),
+		themes,
+		file,
+		config.FromEnv(os.LookupEnv),
+		config.FromFlags(flag.CommandLine),
+	)
+}
+
+func isFlagPassed(name string) bool {
+	passed := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			passed = true
+		}
+	})
+	return passed
+}
+
 func main() {
 	flag.Usage = func() {
-		fmt.Fprintf(flag.CommandLine.Output(), "Usage of termonizer:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage of termonizer: termonizer [flags] [command]\n")
 		flag.PrintDefaults()
-		fmt.Fprintln(flag.CommandLine.Output(), hotkeysDoc)
+		fmt.Fprint(flag.CommandLine.Output(), commandsDoc)
+
+		// the config isn't read yet, so the help shows default keys
+		keymap, err := ui.NewKeymap(nil)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Fprintf(flag.CommandLine.Output(), "\nHotkeys, also F1 or ? in the app:\n\n%s", ui.Help(keymap))
 	}
 
 	flag.Parse()
@@ -60,23 +117,162 @@ func main() {
 
 	ctx := context.Background()
 
-	sqlite, err := storage.NewSQLite(ctx, os.ExpandEnv(*dbPath))
+	// the database path can't come from settings, so the config is loaded twice
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	store, err := storage.Open(ctx, os.ExpandEnv(cfg.DB))
 	if err != nil {
 		panic(err)
 	}
 	defer func() {
-		if err := sqlite.Close(); err != nil {
+		if err := store.Close(); err != nil {
 			panic(err)
 		}
 	}()
 
-	if err := sqlite.Vacuum(ctx); err != nil {
+	settings, err := store.ReadSettings(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	// config and snapshots don't need decrypted content, so they work without the passphrase
+	if !slices.Contains([]string{"config", "backup", "restore"}, flag.Arg(0)) {
+		exitOnError(unlock(ctx, store))
+	}
+
+	recurringRepository := repository.NewRecurring(time.Now, store)
+
+	switch flag.Arg(0) {
+	case "":
+	case "config":
+		exitOnError(configCommand(os.Stdout, settings))
+		return
+	case "recurring":
+		exitOnError(recurringCommand(ctx, os.Stdout, recurringRepository, flag.Args()[1:]))
+		return
+	case "trash":
+		exitOnError(trashCommand(ctx, os.Stdout, repository.NewTrash(store), flag.Args()[1:]))
+		return
+	case "backup", "restore":
+		cfg, err := loadConfig(settings)
+		exitOnError(err)
+
+		backups, err := newBackups(cfg, store)
+		exitOnError(err)
+
+		if flag.Arg(0) == "backup" {
+			exitOnError(backupCommand(ctx, os.Stdout, backups, flag.Args()[1:]))
+		} else {
+			exitOnError(restoreCommand(ctx, os.Stdout, backups, flag.Args()[1:]))
+		}
+		return
+	case "sync":
+		cfg, err := loadConfig(settings)
+		exitOnError(err)
+
+		exitOnError(syncCommand(ctx, os.Stdout, cfg, store))
+		return
+	case "serve":
+		cfg, err := loadConfig(settings)
+		exitOnError(err)
+
+		utils.SetWeekStart(cfg.WeekStart)
+
+		templates, err := repository.NewTemplates(cfg.Templates)
+		exitOnError(err)
+
+		settingsRepository, err := repository.NewSettings(ctx, time.Now, store)
+		exitOnError(err)
+
+		goalsRepository := repository.NewGoalsRepository(time.Now, store, templates, store)
+		exitOnError(serveCommand(ctx, os.Stdout, cfg.APIToken, goalsRepository, settingsRepository, flag.Args()[1:]))
+		return
+	case "daemon":
+		cfg, err := loadConfig(settings)
+		exitOnError(err)
+
+		utils.SetWeekStart(cfg.WeekStart)
+
+		// goals not stored yet remind about times from templates too, as in the app
+		templates, err := repository.NewTemplates(cfg.Templates)
+		exitOnError(err)
+
+		goalsRepository := repository.NewGoalsRepository(time.Now, store, templates, store)
+		exitOnError(daemonCommand(ctx, os.Stdout, goalsRepository, cfg.NotifyCommand))
+		return
+	case "ics", "import", "export":
+		cfg, err := loadConfig(settings)
+		exitOnError(err)
+
+		utils.SetWeekStart(cfg.WeekStart)
+
+		goalsRepository := repository.NewGoalsRepository(time.Now, store, &repository.Templates{}, store)
+		switch flag.Arg(0) {
+		case "ics":
+			exitOnError(icsCommand(ctx, os.Stdout, goalsRepository, flag.Args()[1:]))
+		case "import":
+			exitOnError(importCommand(ctx, os.Stdout, goalsRepository, flag.Args()[1:]))
+		case "export":
+			exitOnError(exportCommand(ctx, os.Stdout, goalsRepository, flag.Args()[1:]))
+		}
+		return
+	case "merge":
+		exitOnError(mergeCommand(ctx, os.Stdout, store, flag.Args()[1:]))
+		return
+	case "encryption":
+		exitOnError(encryptionCommand(ctx, os.Stdout, store, flag.Args()[1:]))
+		return
+	case "search":
+		templates, err := repository.NewTemplates(nil)
+		exitOnError(err)
+
+		goalsRepository := repository.NewGoalsRepository(time.Now, store, templates, store)
+		exitOnError(searchCommand(ctx, os.Stdout, goalsRepository, flag.Args()[1:]))
+		return
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err = loadConfig(settings)
+	if err != nil {
+		panic(err)
+	}
+
+	options, err := uiOptions(cfg)
+	if err != nil {
 		panic(err)
 	}
 
-	goalsRepository := repository.NewGoalsRepository(time.Now, sqlite)
+	utils.SetWeekStart(cfg.WeekStart)
 
-	settingsRepository, err := repository.NewSettings(ctx, time.Now, sqlite)
+	// before vacuum, so there's always a snapshot of the data it could remove
+	if _, ok := store.(*storage.SQLite); ok && cfg.BackupDir != "" {
+		backups, err := newBackups(cfg, store)
+		if err != nil {
+			panic(err)
+		}
+
+		if _, err := backups.Daily(ctx); err != nil {
+			panic(err)
+		}
+	}
+
+	if err := store.Vacuum(ctx, time.Now()); err != nil {
+		panic(err)
+	}
+
+	templates, err := repository.NewTemplates(cfg.Templates)
+	if err != nil {
+		panic(err)
+	}
+
+	goalsRepository := repository.NewGoalsRepository(time.Now, store, templates, store)
+
+	settingsRepository, err := repository.NewSettings(ctx, time.Now, store)
 	if err != nil {
 		panic(err)
 	}
@@ -85,7 +281,46 @@ func main() {
 		panic(err)
 	}
 
-	if err = ui.NewCLI(ctx, time.Now, goalsRepository, settingsRepository).Run(); err != nil {
+	if err = ui.NewCLI(ctx, time.Now, options, goalsRepository, settingsRepository, recurringRepository).Run(ctx); err != nil {
 		panic(err)
 	}
 }
+
+// exitOnError is used by commands, so errors are shown without a stack trace
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func uiOptions(cfg *config.Config) (ui.Options, error) {
+	themes := theme.NewThemes()
+	if err := themes.Define(cfg.Themes); err != nil {
+		return ui.Options{}, err
+	}
+
+	currentTheme, err := themes.Get(cfg.Theme)
+	if err != nil {
+		return ui.Options{}, err
+	}
+
+	keymap, err := ui.NewKeymap(cfg.Keymap)
+	if err != nil {
+		return ui.Options{}, fmt.Errorf("invalid keymap: %w", err)
+	}
+
+	return ui.Options{
+		Theme:             currentTheme,
+		Keymap:            keymap,
+		Periods:           cfg.Periods,
+		Placeholder:       cfg.Placeholder,
+		FuturePlaceholder: cfg.FuturePlaceholder,
+		Autosave:          cfg.Autosave,
+		AutosaveDelay:     cfg.AutosaveDelay,
+		EditingMode:       cfg.EditingMode,
+		ZenWidth:          cfg.ZenWidth,
+		ZenParent:         cfg.ZenParent,
+		NotifyCommand:     cfg.NotifyCommand,
+	}, nil
+}
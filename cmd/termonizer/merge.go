@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/merge"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/storage"
+	"io"
+	"os"
+	"time"
+)
+
+const mergeUsage = `usage: termonizer merge other.db`
+
+// mergeCommand reconciles the database with a copy changed on another machine, both have the same goals afterwards
+func mergeCommand(ctx context.Context, out io.Writer, store storage.Storage, args []string) error {
+	if len(args) != 1 {
+		return errors.New(mergeUsage)
+	}
+
+	ours, ok := store.(*storage.SQLite)
+	if !ok {
+		return errors.New("merge is only supported by the sqlite storage, use sync for files")
+	}
+
+	// the other database would be created otherwise
+	if _, err := os.Stat(args[0]); err != nil {
+		return fmt.Errorf("failed to open %s: %w", args[0], err)
+	}
+
+	theirs, err := storage.NewSQLite(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", args[0], err)
+	}
+	defer theirs.Close()
+
+	if encrypted, err := theirs.IsEncrypted(ctx); err != nil {
+		return err
+	} else if encrypted {
+		passphrase, err := readPassphrase("Passphrase of " + args[0] + ": ")
+		if err != nil {
+			return err
+		}
+
+		if err := theirs.Unlock(ctx, passphrase); err != nil {
+			return err
+		}
+	}
+
+	result, err := merge.Databases(ctx, ours, theirs, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to merge: %w", err)
+	}
+
+	fmt.Fprintf(out, "%d added, %d updated, %d merged, %d trashed\n", result.Added, result.Updated, result.Merged, result.Trashed)
+
+	for _, goal := range result.Conflicts {
+		fmt.Fprintf(out, "conflict in %s %s, resolve it in the goal\n", model.PeriodName(goal.Period), goal.FormatStart())
+	}
+
+	return nil
+}
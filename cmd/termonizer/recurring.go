@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/repository"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const recurringUsage = `usage: termonizer recurring list
+       termonizer recurring add <schedule> <content>
+       termonizer recurring remove <id>
+
+schedules: daily, weekdays, monday..sunday, weekly, monthly:N, quarterly`
+
+func recurringCommand(ctx context.Context, out io.Writer, recurring *repository.Recurring, args []string) error {
+	if len(args) == 0 {
+		return errors.New(recurringUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		items, err := recurring.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tSCHEDULE\tCONTENT")
+		for _, item := range items {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", item.ID[:8], item.Schedule, strings.ReplaceAll(item.Content, "\n", "\\n"))
+		}
+		return w.Flush()
+	case "add":
+		if len(args) < 3 {
+			return errors.New(recurringUsage)
+		}
+
+		item, err := recurring.Add(ctx, args[1], strings.Join(args[2:], " "))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "added %s\n", item.ID[:8])
+		return nil
+	case "remove":
+		if len(args) != 2 {
+			return errors.New(recurringUsage)
+		}
+
+		return recurring.Remove(ctx, args[1])
+	default:
+		return errors.New(recurringUsage)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/repository"
+	"io"
+	"strings"
+)
+
+// searchCommand prints matching lines of goals containing the query
+func searchCommand(ctx context.Context, out io.Writer, goals *repository.Goals, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: termonizer search <query>")
+	}
+
+	query := strings.Join(args, " ")
+	found, err := goals.Search(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, goal := range found {
+		fmt.Fprintf(out, "%s %s\n", model.PeriodName(goal.Period), goal.FormatStart())
+		for _, line := range strings.Split(goal.Content, "\n") {
+			if strings.Contains(strings.ToLower(line), strings.ToLower(query)) {
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+		}
+	}
+
+	return nil
+}
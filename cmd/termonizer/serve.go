@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/api"
+	"github.com/nvbn/termonizer/internal/repository"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// serveCommand runs the http api until interrupted, the token is generated when api_token isn't set
+func serveCommand(ctx context.Context, out io.Writer, token string, goals *repository.Goals, settings *repository.Settings, args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := flags.String("listen", "127.0.0.1:8421", "address to listen on")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if token == "" {
+		random := make([]byte, 16)
+		if _, err := rand.Read(random); err != nil {
+			return fmt.Errorf("failed to generate token: %w", err)
+		}
+
+		token = hex.EncodeToString(random)
+		fmt.Fprintf(out, "api_token isn't set, use %s for this run\n", token)
+	}
+
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	server := &http.Server{
+		Handler:           api.NewServer(token, goals, settings),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(out, "serving on http://%s/api\n", listener.Addr())
+	fmt.Fprintf(out, "read-only calendar: http://%s/api/calendar.ics?token=%s\n", listener.Addr(), api.CalendarToken(token))
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/gitsync"
+	"github.com/nvbn/termonizer/internal/storage"
+	"io"
+	"os"
+)
+
+// syncCommand commits goals stored in files and syncs them with the git remote
+func syncCommand(ctx context.Context, out io.Writer, cfg *config.Config, store storage.Storage) error {
+	files, ok := store.(*storage.Files)
+	if !ok {
+		return errors.New("sync is only supported by the files storage, use -db dir:///path")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	result, err := gitsync.New(files.Dir(), cfg.SyncRemote, cfg.SyncBranch).Sync(ctx, "sync from "+hostname)
+	if err != nil {
+		return fmt.Errorf("failed to sync: %w", err)
+	}
+
+	if result.Committed {
+		fmt.Fprintln(out, "committed local changes")
+	}
+
+	if result.Pushed {
+		fmt.Fprintf(out, "synced with %s\n", cfg.SyncRemote)
+	} else if cfg.SyncRemote == "" {
+		fmt.Fprintln(out, "sync_remote isn't set, changes are only committed locally")
+	}
+
+	for _, path := range result.Overridden {
+		fmt.Fprintf(out, "conflict in %s, the local version is kept\n", path)
+	}
+
+	for _, path := range result.Deleted {
+		fmt.Fprintf(out, "%s was deleted on one side and edited on the other, the edited version is kept\n", path)
+	}
+
+	for _, path := range result.Conflicts {
+		fmt.Fprintf(out, "conflict in %s, resolve it in the goal\n", path)
+	}
+
+	return nil
+}
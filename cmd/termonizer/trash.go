@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/repository"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const trashUsage = `usage: termonizer trash list
+       termonizer trash restore <id>
+       termonizer trash purge [<id>]`
+
+// shortID returns a prefix of uuids, ids of goals stored in files are paths and shown as is
+func shortID(id string) string {
+	if _, err := uuid.Parse(id); err == nil {
+		return id[:8]
+	}
+
+	return id
+}
+
+func trashCommand(ctx context.Context, out io.Writer, trash *repository.Trash, args []string) error {
+	if len(args) == 0 {
+		return errors.New(trashUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		trashed, err := trash.List(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tPERIOD\tSTART\tDELETED\tCONTENT")
+		for _, goal := range trashed {
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%s\t%s\t%s\n",
+				shortID(goal.ID),
+				model.PeriodName(goal.Period),
+				goal.FormatStart(),
+				goal.Deleted.Format("2006-01-02 15:04"),
+				strings.ReplaceAll(goal.Content, "\n", "\\n"),
+			)
+		}
+		return w.Flush()
+	case "restore":
+		if len(args) != 2 {
+			return errors.New(trashUsage)
+		}
+
+		goal, err := trash.Restore(ctx, args[1])
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "restored %s %s\n", model.PeriodName(goal.Period), goal.FormatStart())
+		return nil
+	case "purge":
+		if len(args) > 2 {
+			return errors.New(trashUsage)
+		}
+
+		id := ""
+		if len(args) == 2 {
+			id = args[1]
+		}
+
+		return trash.Purge(ctx, id)
+	default:
+		return errors.New(trashUsage)
+	}
+}
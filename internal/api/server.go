@@ -0,0 +1,451 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/ical"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/repository"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 500
+	// calendarPath accepts CalendarToken in the query too, calendar apps can't send headers
+	calendarPath = "/api/calendar.ics"
+)
+
+type goalsRepository interface {
+	FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error)
+	All(ctx context.Context) ([]model.Goal, error)
+	Find(ctx context.Context, id string) (model.Goal, error)
+	Update(ctx context.Context, goal model.Goal) error
+	Search(ctx context.Context, query string) ([]model.Goal, error)
+}
+
+type settingsRepository interface {
+	GetAmountForPeriod(period model.Period) int
+	SetAmountForPeriod(ctx context.Context, period model.Period, amount int) error
+	GetMarkdownPreview() bool
+	SetMarkdownPreview(ctx context.Context, enabled bool) error
+	GetLayout() model.Layout
+	SetLayout(ctx context.Context, layout model.Layout) error
+}
+
+type goalJSON struct {
+	ID      string    `json:"id"`
+	Period  string    `json:"period"`
+	Start   string    `json:"start"`
+	Title   string    `json:"title"`
+	Content string    `json:"content"`
+	Updated time.Time `json:"updated"`
+	ETag    string    `json:"etag"`
+}
+
+type goalsPageJSON struct {
+	Goals  []goalJSON `json:"goals"`
+	Total  int        `json:"total"`
+	Offset int        `json:"offset"`
+	Limit  int        `json:"limit"`
+}
+
+// goalUpdateJSON period and start are only needed for goals that aren't stored yet
+type goalUpdateJSON struct {
+	Content *string `json:"content"`
+	Period  string  `json:"period"`
+	Start   string  `json:"start"`
+}
+
+type settingsJSON struct {
+	PeriodToAmount  map[string]int `json:"period_to_amount"`
+	MarkdownPreview *bool          `json:"markdown_preview"`
+	Layout          *model.Layout  `json:"layout"`
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+// Server exposes goals and settings over http, every request should have "Authorization: Bearer <token>"
+type Server struct {
+	token    string
+	goals    goalsRepository
+	settings settingsRepository
+	mux      *http.ServeMux
+
+	// repositories aren't safe for concurrent use and the etag check should be atomic with the update
+	mu sync.Mutex
+}
+
+func NewServer(token string, goals goalsRepository, settings settingsRepository) *Server {
+	s := &Server{
+		token:    token,
+		goals:    goals,
+		settings: settings,
+		mux:      http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /api/goals", s.listGoals)
+	s.mux.HandleFunc("GET /api/goals/{id}", s.getGoal)
+	s.mux.HandleFunc("PUT /api/goals/{id}", s.updateGoal)
+	s.mux.HandleFunc("GET /api/search", s.search)
+	s.mux.HandleFunc("GET /api/export", s.export)
+	s.mux.HandleFunc("GET "+calendarPath, s.calendar)
+	s.mux.HandleFunc("GET /api/settings", s.getSettings)
+	s.mux.HandleFunc("PUT /api/settings", s.updateSettings)
+
+	return s
+}
+
+// CalendarToken is derived from the api token and only gives access to the calendar, so the api token doesn't end up
+// in urls, proxy logs and settings of calendar apps
+func CalendarToken(token string) string {
+	sum := sha256.Sum256([]byte("calendar\x00" + token))
+	return hex.EncodeToString(sum[:16])
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	expected := s.token
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok && r.URL.Path == calendarPath && r.Method == http.MethodGet {
+		token, ok, expected = r.URL.Query().Get("token"), true, CalendarToken(s.token)
+	}
+
+	if !ok || s.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mux.ServeHTTP(w, r)
+}
+
+// etag changes with the content, goals padded by FindForPeriod get new ids and etags on every request,
+// but they aren't stored, so they're created without If-Match
+func etag(goal model.Goal) string {
+	sum := sha256.Sum256([]byte(goal.ID + "\x00" + goal.Content))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+func toJSON(goal model.Goal) goalJSON {
+	return goalJSON{
+		ID:      goal.ID,
+		Period:  strings.ToLower(model.PeriodName(goal.Period)),
+		Start:   goal.Start.Format("2006-01-02"),
+		Title:   goal.FormatStart(),
+		Content: goal.Content,
+		Updated: goal.Updated,
+		ETag:    etag(goal),
+	}
+}
+
+func toJSONs(goals []model.Goal) []goalJSON {
+	out := make([]goalJSON, 0, len(goals))
+	for _, goal := range goals {
+		out = append(out, toJSON(goal))
+	}
+
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// the status is already sent, so encoding errors can't be reported
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorJSON{Error: err.Error()})
+}
+
+func queryInt(r *http.Request, name string, fallback int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("%s should be a non-negative number", name)
+	}
+
+	return parsed, nil
+}
+
+// listGoals returns goals of the period from the newest with the current and the next ones padded like in the ui
+func (s *Server) listGoals(w http.ResponseWriter, r *http.Request) {
+	period, err := model.ParsePeriod(r.URL.Query().Get("period"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, err := queryInt(r, "limit", defaultLimit)
+	if err != nil || limit == 0 || limit > maxLimit {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("limit should be between 1 and %d", maxLimit))
+		return
+	}
+
+	goals, err := s.goals.FindForPeriod(r.Context(), period)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	page := goals[min(offset, len(goals)):min(offset+limit, len(goals))]
+	writeJSON(w, http.StatusOK, goalsPageJSON{
+		Goals:  toJSONs(page),
+		Total:  len(goals),
+		Offset: offset,
+		Limit:  limit,
+	})
+}
+
+func (s *Server) getGoal(w http.ResponseWriter, r *http.Request) {
+	goal, err := s.goals.Find(r.Context(), r.PathValue("id"))
+	if errors.Is(err, repository.ErrGoalNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("ETag", etag(goal))
+	if r.Header.Get("If-None-Match") == etag(goal) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toJSON(goal))
+}
+
+// newGoal creates a goal that isn't stored yet, e.g. padded by the list, only one goal could exist for the start
+func (s *Server) newGoal(ctx context.Context, id string, update goalUpdateJSON) (model.Goal, int, error) {
+	period, err := model.ParsePeriod(update.Period)
+	if err != nil {
+		return model.Goal{}, http.StatusBadRequest, fmt.Errorf("period and start are required for a new goal: %w", err)
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", update.Start, time.Local)
+	if err != nil {
+		return model.Goal{}, http.StatusBadRequest, fmt.Errorf("period and start are required for a new goal: %w", err)
+	}
+
+	goal := model.NewGoal(period, start)
+	goal.ID = id
+
+	stored, err := s.goals.All(ctx)
+	if err != nil {
+		return model.Goal{}, http.StatusInternalServerError, err
+	}
+
+	for _, existing := range stored {
+		if existing.Period == goal.Period && existing.CompareStart(goal.Start) == 0 {
+			return model.Goal{}, http.StatusConflict, fmt.Errorf("%s %s already has goal %s", model.PeriodName(goal.Period), goal.FormatStart(), existing.ID)
+		}
+	}
+
+	return goal, http.StatusOK, nil
+}
+
+// updateGoal requires If-Match with the etag of a stored goal, so changes made in between aren't overwritten
+func (s *Server) updateGoal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	var update goalUpdateJSON
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	} else if update.Content == nil {
+		writeError(w, http.StatusBadRequest, errors.New("content is required"))
+		return
+	}
+
+	goal, err := s.goals.Find(ctx, id)
+	if errors.Is(err, repository.ErrGoalNotFound) {
+		var status int
+		if goal, status, err = s.newGoal(ctx, id, update); err != nil {
+			writeError(w, status, err)
+			return
+		}
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	} else if match := r.Header.Get("If-Match"); match == "" {
+		writeError(w, http.StatusPreconditionRequired, errors.New("If-Match header with the goal etag is required"))
+		return
+	} else if match != "*" && match != etag(goal) {
+		writeError(w, http.StatusPreconditionFailed, errors.New("the goal was changed, fetch it again"))
+		return
+	}
+
+	goal.Content = *update.Content
+	if err := s.goals.Update(ctx, goal); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the repository sets the update time
+	if updated, err := s.goals.Find(ctx, id); err == nil {
+		goal = updated
+	}
+
+	w.Header().Set("ETag", etag(goal))
+	writeJSON(w, http.StatusOK, toJSON(goal))
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, errors.New("q is required"))
+		return
+	}
+
+	goals, err := s.goals.Search(r.Context(), query)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, goalsPageJSON{Goals: toJSONs(goals), Total: len(goals), Limit: len(goals)})
+}
+
+// export returns all stored goals as json or as a single markdown document
+func (s *Server) export(w http.ResponseWriter, r *http.Request) {
+	goals, err := s.goals.All(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, toJSONs(goals))
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		for _, goal := range goals {
+			fmt.Fprintf(w, "# %s %s\n\n%s\n\n", model.PeriodName(goal.Period), goal.FormatStart(), strings.TrimRight(goal.Content, "\n"))
+		}
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("format should be json or markdown"))
+	}
+}
+
+// calendar is an iCalendar feed of all goals, "?periods=week,day" limits exported periods
+func (s *Server) calendar(w http.ResponseWriter, r *http.Request) {
+	periods := model.Periods
+	if names := r.URL.Query().Get("periods"); names != "" {
+		periods = make([]model.Period, 0)
+		for _, name := range strings.Split(names, ",") {
+			period, err := model.ParsePeriod(name)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			periods = append(periods, period)
+		}
+	}
+
+	goals, err := s.goals.All(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	// the response is already started, errors can't be reported
+	_ = ical.Write(w, slices.DeleteFunc(goals, func(goal model.Goal) bool {
+		return !slices.Contains(periods, goal.Period)
+	}), time.Now())
+}
+
+func (s *Server) settingsJSON() settingsJSON {
+	periodToAmount := make(map[string]int)
+	for _, period := range model.Periods {
+		periodToAmount[strings.ToLower(model.PeriodName(period))] = s.settings.GetAmountForPeriod(period)
+	}
+
+	markdownPreview := s.settings.GetMarkdownPreview()
+	layout := s.settings.GetLayout()
+	return settingsJSON{PeriodToAmount: periodToAmount, MarkdownPreview: &markdownPreview, Layout: &layout}
+}
+
+func (s *Server) getSettings(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.settingsJSON())
+}
+
+// updateSettings changes only passed settings
+func (s *Server) updateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var update settingsJSON
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid body: %w", err))
+		return
+	}
+
+	// everything is validated first, so invalid requests don't change anything
+	periodToAmount := make(map[model.Period]int)
+	for name, amount := range update.PeriodToAmount {
+		period, err := model.ParsePeriod(name)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		} else if amount < 1 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("amount for %s should be positive", name))
+			return
+		}
+
+		periodToAmount[period] = amount
+	}
+
+	if update.Layout != nil && !slices.Contains(model.Layouts, *update.Layout) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown layout %s", *update.Layout))
+		return
+	}
+
+	for period, amount := range periodToAmount {
+		if err := s.settings.SetAmountForPeriod(ctx, period, amount); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if update.MarkdownPreview != nil {
+		if err := s.settings.SetMarkdownPreview(ctx, *update.MarkdownPreview); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if update.Layout != nil {
+		if err := s.settings.SetLayout(ctx, *update.Layout); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, s.settingsJSON())
+}
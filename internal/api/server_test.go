@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/repository"
+	"github.com/nvbn/termonizer/internal/storage"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testToken = "secret"
+
+func newTestServer(t *testing.T) *Server {
+	ctx := t.Context()
+
+	store, err := storage.NewSQLite(ctx, ":memory:")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	now := func() time.Time { return time.Date(2024, 12, 10, 10, 0, 0, 0, time.Local) }
+
+	settings, err := repository.NewSettings(ctx, now, store)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	goals := repository.NewGoalsRepository(now, store, &repository.Templates{}, store)
+
+	return NewServer(testToken, goals, settings)
+}
+
+func request(t *testing.T, s *Server, method string, url string, body string, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, url, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+testToken)
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w
+}
+
+func decode[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	var value T
+	if err := json.NewDecoder(w.Body).Decode(&value); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return value
+}
+
+func TestServer_Auth(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, header := range []string{"", "Bearer", "Bearer wrong", testToken} {
+		r := httptest.NewRequest(http.MethodGet, "/api/settings", nil)
+		r.Header.Set("Authorization", header)
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for %q, got %d", header, w.Code)
+		}
+	}
+}
+
+func TestServer_Goals(t *testing.T) {
+	s := newTestServer(t)
+
+	w := request(t, s, http.MethodGet, "/api/goals?period=week&limit=1", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	page := decode[goalsPageJSON](t, w)
+	if page.Total != 2 || len(page.Goals) != 1 || page.Goals[0].Title != "2024-12-16 W51" {
+		t.Fatalf("expected the first of two padded weeks, got %+v", page)
+	}
+
+	// padded goals aren't stored yet
+	padded := page.Goals[0]
+	if w := request(t, s, http.MethodGet, "/api/goals/"+padded.ID, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+
+	w = request(t, s, http.MethodPut, "/api/goals/"+padded.ID, `{"content": "* plan"}`, nil)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without period and start, got %d", w.Code)
+	}
+
+	w = request(t, s, http.MethodPut, "/api/goals/"+padded.ID, `{"content": "* plan", "period": "week", "start": "2024-12-18"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	created := decode[goalJSON](t, w)
+	if created.Start != "2024-12-16" || created.Content != "* plan" {
+		t.Errorf("expected created goal, got %+v", created)
+	}
+
+	w = request(t, s, http.MethodPut, "/api/goals/another", `{"content": "* plan", "period": "week", "start": "2024-12-16"}`, nil)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for the second goal of the week, got %d", w.Code)
+	}
+
+	if w := request(t, s, http.MethodPut, "/api/goals/"+created.ID, `{"content": "* changed"}`, nil); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("expected 428 without If-Match, got %d", w.Code)
+	}
+
+	w = request(t, s, http.MethodPut, "/api/goals/"+created.ID, `{"content": "* changed"}`, map[string]string{"If-Match": created.ETag})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	// the etag is outdated after the update
+	w = request(t, s, http.MethodPut, "/api/goals/"+created.ID, `{"content": "* lost"}`, map[string]string{"If-Match": created.ETag})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected 412, got %d", w.Code)
+	}
+
+	w = request(t, s, http.MethodGet, "/api/goals/"+created.ID, "", nil)
+	if goal := decode[goalJSON](t, w); goal.Content != "* changed" || w.Header().Get("ETag") != goal.ETag {
+		t.Errorf("expected changed goal with etag, got %+v", goal)
+	}
+
+	w = request(t, s, http.MethodGet, "/api/search?q=chan", "", nil)
+	if found := decode[goalsPageJSON](t, w); found.Total != 1 || found.Goals[0].ID != created.ID {
+		t.Errorf("expected found goal, got %+v", found)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/calendar.ics?periods=week&token="+CalendarToken(testToken), nil)
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if !strings.Contains(w.Body.String(), "UID:"+created.ID+"@termonizer") {
+		t.Errorf("expected the goal in the calendar, got %q", w.Body.String())
+	}
+
+	// the api token isn't accepted in urls, and the calendar token gives access only to the calendar
+	for _, path := range []string{"/api/calendar.ics?token=" + testToken, "/api/goals?token=" + CalendarToken(testToken)} {
+		w = httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", path, w.Code)
+		}
+	}
+
+	w = request(t, s, http.MethodGet, "/api/export?format=markdown", "", nil)
+	if w.Body.String() != "# Week 2024-12-16 W51\n\n* changed\n\n" {
+		t.Errorf("unexpected export %q", w.Body.String())
+	}
+}
+
+func TestServer_Settings(t *testing.T) {
+	s := newTestServer(t)
+
+	if w := request(t, s, http.MethodPut, "/api/settings", `{"period_to_amount": {"week": 0}}`, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+
+	if w := request(t, s, http.MethodPut, "/api/settings", `{"layout": "grid"}`, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+
+	w := request(t, s, http.MethodPut, "/api/settings", `{"period_to_amount": {"Week": 2}, "markdown_preview": true, "layout": "rows"}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body)
+	}
+
+	settings := decode[settingsJSON](t, request(t, s, http.MethodGet, "/api/settings", "", nil))
+	if settings.PeriodToAmount["week"] != 2 || settings.PeriodToAmount["day"] != 5 || !*settings.MarkdownPreview ||
+		*settings.Layout != model.LayoutRows {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+
+	if s.settings.GetAmountForPeriod(model.Week) != 2 {
+		t.Errorf("expected the setting to be stored")
+	}
+}
@@ -0,0 +1,548 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/utils"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Source string
+
+const (
+	SourceDefault  Source = "default"
+	SourceSettings Source = "settings"
+	SourceFile     Source = "file"
+	SourceEnv      Source = "env"
+	SourceFlag     Source = "flag"
+)
+
+type AutosavePolicy string
+
+const (
+	// AutosaveImmediate saves a goal on every change
+	AutosaveImmediate AutosavePolicy = "immediate"
+	// AutosaveDelayed saves a goal when there were no changes for AutosaveDelay
+	AutosaveDelayed AutosavePolicy = "delayed"
+	// AutosaveBlur saves a goal when the editor loses focus
+	AutosaveBlur AutosavePolicy = "blur"
+)
+
+type EditingMode string
+
+const (
+	EditingDefault EditingMode = "default"
+	// EditingVim enables modal editing with normal, insert and visual modes
+	EditingVim EditingMode = "vim"
+)
+
+const (
+	KeyDB                = "db"
+	KeyPeriods           = "periods"
+	KeyTheme             = "theme"
+	KeyPlaceholder       = "placeholder"
+	KeyFuturePlaceholder = "future_placeholder"
+	KeyWeekStart         = "week_start"
+	KeyAutosave          = "autosave"
+	KeyAutosaveDelay     = "autosave_delay"
+	KeyEditingMode       = "editing_mode"
+	KeyZenWidth          = "zen_width"
+	KeyZenParent         = "zen_parent"
+	KeyTemplatesDir      = "templates_dir"
+	KeyBackupDir         = "backup_dir"
+	KeyBackupDaily       = "backup_daily"
+	KeyBackupWeekly      = "backup_weekly"
+	KeyBackupMonthly     = "backup_monthly"
+	KeySyncRemote        = "sync_remote"
+	KeySyncBranch        = "sync_branch"
+	KeyAPIToken          = "api_token"
+	KeyNotifyCommand     = "notify_command"
+
+	keymapPrefix   = "keymap."
+	templatePrefix = "template."
+	envPrefix      = "TERMONIZER_"
+)
+
+// keys that could be set by any source, keymap entries are added dynamically
+var keys = []string{
+	KeyDB,
+	KeyPeriods,
+	KeyTheme,
+	KeyPlaceholder,
+	KeyFuturePlaceholder,
+	KeyWeekStart,
+	KeyAutosave,
+	KeyAutosaveDelay,
+	KeyEditingMode,
+	KeyZenWidth,
+	KeyZenParent,
+	KeyTemplatesDir,
+	KeyBackupDir,
+	KeyBackupDaily,
+	KeyBackupWeekly,
+	KeyBackupMonthly,
+	KeySyncRemote,
+	KeySyncBranch,
+	KeyAPIToken,
+	KeyNotifyCommand,
+}
+
+var listKeys = map[string]bool{
+	KeyPeriods:       true,
+	KeyNotifyCommand: true,
+}
+
+const defaultPlaceholder = `* a things to do
+* a thing to achieve
+
+--
+Some notes. This is just a placeholder in some opinionated format.
+`
+
+const defaultFuturePlaceholder = `Goals and notes for the future`
+
+// Layer is a set of raw values from one source, every value is a list to support keymap and periods
+type Layer struct {
+	Source Source
+	Values map[string][]string
+	Themes map[string]map[string]string
+}
+
+type Config struct {
+	DB                string
+	Periods           []model.Period
+	Theme             string
+	Themes            map[string]map[string]string
+	Keymap            map[string][]string
+	Placeholder       string
+	FuturePlaceholder string
+	WeekStart         time.Weekday
+	Autosave          AutosavePolicy
+	AutosaveDelay     time.Duration
+	EditingMode       EditingMode
+	// ZenWidth is the width of the goal in the zen mode, ZenParent shows the goal of the longer period next to it
+	ZenWidth  int
+	ZenParent bool
+	// Templates are used for new goals, set explicitly or read from <period>.md in TemplatesDir
+	Templates    map[model.Period]string
+	TemplatesDir string
+	// BackupDir is where daily snapshots are stored, empty disables automatic backups
+	BackupDir     string
+	BackupDaily   int
+	BackupWeekly  int
+	BackupMonthly int
+	// SyncRemote is a git remote for "termonizer sync" of goals stored in files, empty only commits locally
+	SyncRemote string
+	SyncBranch string
+	// APIToken is required by "termonizer serve", a random one is generated on start when it's empty
+	APIToken string
+	// NotifyCommand is run with the text of a reminder as the last argument, empty disables notifications
+	NotifyCommand []string
+
+	values  map[string][]string
+	sources map[string]Source
+}
+
+func Defaults() Layer {
+	return Layer{
+		Source: SourceDefault,
+		Values: map[string][]string{
+			KeyDB:                {"${HOME}/.termonizer.db"},
+			KeyPeriods:           {"year", "quarter", "week", "day"},
+			KeyTheme:             {"dark"},
+			KeyPlaceholder:       {defaultPlaceholder},
+			KeyFuturePlaceholder: {defaultFuturePlaceholder},
+			KeyWeekStart:         {"monday"},
+			KeyAutosave:          {string(AutosaveImmediate)},
+			KeyAutosaveDelay:     {"1s"},
+			KeyEditingMode:       {string(EditingDefault)},
+			KeyZenWidth:          {"100"},
+			KeyZenParent:         {"true"},
+			KeyTemplatesDir:      {utils.ConfigPath("templates")},
+			KeyBackupDir:         {"${HOME}/.termonizer-backups"},
+			KeyBackupDaily:       {"7"},
+			KeyBackupWeekly:      {"4"},
+			KeyBackupMonthly:     {"12"},
+			KeySyncRemote:        {""},
+			KeySyncBranch:        {"main"},
+			KeyAPIToken:          {""},
+			KeyNotifyCommand:     {"notify-send", "termonizer"},
+		},
+	}
+}
+
+// FromFile reads a toml config, a missing file is an empty layer:
+//
+//	db = "~/notes/termonizer.db"
+//	periods = ["week", "day"]
+//	theme = "mine"
+//
+//	[keymap]
+//	focus_left = ["Shift+Alt+Left", "Alt+Rune[h]"]
+//
+//	[templates]
+//	day = "# {{weekday}}"
+//
+//	[themes.mine]
+//	base = "solarized"
+func FromFile(path string) (Layer, error) {
+	layer := Layer{Source: SourceFile, Values: make(map[string][]string)}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return layer, nil
+	} else if err != nil {
+		return layer, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var raw map[string]any
+	if _, err := toml.Decode(string(content), &raw); err != nil {
+		return layer, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	for key, value := range raw {
+		switch key {
+		case "themes":
+			var file struct {
+				Themes map[string]map[string]string `toml:"themes"`
+			}
+			if _, err := toml.Decode(string(content), &file); err != nil {
+				return layer, fmt.Errorf("failed to parse themes: %w", err)
+			}
+			layer.Themes = file.Themes
+		case "keymap":
+			keymap, ok := value.(map[string]any)
+			if !ok {
+				return layer, fmt.Errorf("keymap should be a table")
+			}
+
+			for action, bindings := range keymap {
+				values, err := toStrings(bindings)
+				if err != nil {
+					return layer, fmt.Errorf("keymap %s: %w", action, err)
+				}
+				layer.Values[keymapPrefix+action] = values
+			}
+		case "templates":
+			templates, ok := value.(map[string]any)
+			if !ok {
+				return layer, fmt.Errorf("templates should be a table")
+			}
+
+			for period, template := range templates {
+				text, ok := template.(string)
+				if !ok {
+					return layer, fmt.Errorf("template %s should be a string", period)
+				}
+				layer.Values[templatePrefix+period] = []string{text}
+			}
+		default:
+			if !slices.Contains(keys, key) {
+				return layer, fmt.Errorf("unknown config key %s", key)
+			}
+
+			values, err := toStrings(value)
+			if err != nil {
+				return layer, fmt.Errorf("%s: %w", key, err)
+			}
+			layer.Values[key] = values
+		}
+	}
+
+	return layer, nil
+}
+
+// ThemesFromFile reads [themes.*] tables of themes.toml, which was used for themes before the config file,
+// a missing file is an empty layer
+func ThemesFromFile(path string) (Layer, error) {
+	layer := Layer{Source: SourceFile, Values: make(map[string][]string)}
+
+	var file struct {
+		Themes map[string]map[string]string `toml:"themes"`
+	}
+	if _, err := toml.DecodeFile(path, &file); errors.Is(err, fs.ErrNotExist) {
+		return layer, nil
+	} else if err != nil {
+		return layer, fmt.Errorf("failed to parse themes: %w", err)
+	}
+
+	layer.Themes = file.Themes
+	return layer, nil
+}
+
+func toStrings(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of strings, got %v", v)
+			}
+			out = append(out, str)
+		}
+		return out, nil
+	case int64, float64, bool:
+		return []string{fmt.Sprint(v)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(key)
+}
+
+// FromEnv reads TERMONIZER_<KEY> variables, lists are comma separated
+func FromEnv(lookupEnv func(string) (string, bool)) Layer {
+	layer := Layer{Source: SourceEnv, Values: make(map[string][]string)}
+
+	for _, key := range keys {
+		if value, ok := lookupEnv(envName(key)); ok {
+			layer.Values[key] = splitValue(key, value)
+		}
+	}
+
+	return layer
+}
+
+// FromFlags reads only explicitly passed flags named as config keys with dashes
+func FromFlags(flags *flag.FlagSet) Layer {
+	layer := Layer{Source: SourceFlag, Values: make(map[string][]string)}
+
+	flags.Visit(func(f *flag.Flag) {
+		key := strings.ReplaceAll(f.Name, "-", "_")
+		if slices.Contains(keys, key) {
+			layer.Values[key] = splitValue(key, f.Value.String())
+		}
+	})
+
+	return layer
+}
+
+// FromSettings reads settings stored in the database with config keys as ids
+func FromSettings(settings []model.Setting) Layer {
+	layer := Layer{Source: SourceSettings, Values: make(map[string][]string)}
+
+	for _, setting := range settings {
+		// the database path can't come from the database itself
+		if setting.ID != KeyDB && (slices.Contains(keys, setting.ID) ||
+			strings.HasPrefix(setting.ID, keymapPrefix) ||
+			strings.HasPrefix(setting.ID, templatePrefix)) {
+			layer.Values[setting.ID] = splitValue(setting.ID, setting.Value)
+		}
+	}
+
+	return layer
+}
+
+func splitValue(key string, value string) []string {
+	if !listKeys[key] {
+		return []string{value}
+	}
+
+	values := strings.Split(value, ",")
+	for n := range values {
+		values[n] = strings.TrimSpace(values[n])
+	}
+
+	return values
+}
+
+// Resolve merges layers, the later layer wins
+func Resolve(layers ...Layer) (*Config, error) {
+	c := &Config{
+		Themes:    make(map[string]map[string]string),
+		Keymap:    make(map[string][]string),
+		Templates: make(map[model.Period]string),
+		values:    make(map[string][]string),
+		sources:   make(map[string]Source),
+	}
+
+	for _, layer := range layers {
+		for key, value := range layer.Values {
+			c.values[key] = value
+			c.sources[key] = layer.Source
+		}
+
+		maps.Copy(c.Themes, layer.Themes)
+	}
+
+	if err := c.parse(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Config) single(key string) string {
+	value := c.values[key]
+	if len(value) == 0 {
+		return ""
+	}
+
+	return value[len(value)-1]
+}
+
+func (c *Config) parse() error {
+	c.DB = c.single(KeyDB)
+	c.Theme = c.single(KeyTheme)
+	c.Placeholder = c.single(KeyPlaceholder)
+	c.FuturePlaceholder = c.single(KeyFuturePlaceholder)
+
+	c.Periods = make([]model.Period, 0, len(model.Periods))
+	for _, name := range c.values[KeyPeriods] {
+		period, err := model.ParsePeriod(name)
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(c.Periods, period) {
+			return fmt.Errorf("period %s is enabled twice", name)
+		}
+
+		c.Periods = append(c.Periods, period)
+	}
+	if len(c.Periods) == 0 {
+		return fmt.Errorf("at least one period should be enabled")
+	}
+	// panels are always shown from the longest to the shortest period
+	slices.Sort(c.Periods)
+
+	weekStart, err := parseWeekday(c.single(KeyWeekStart))
+	if err != nil {
+		return err
+	}
+	c.WeekStart = weekStart
+
+	c.Autosave = AutosavePolicy(c.single(KeyAutosave))
+	if !slices.Contains([]AutosavePolicy{AutosaveImmediate, AutosaveDelayed, AutosaveBlur}, c.Autosave) {
+		return fmt.Errorf("unknown autosave policy %s", c.Autosave)
+	}
+
+	c.AutosaveDelay, err = time.ParseDuration(c.single(KeyAutosaveDelay))
+	if err != nil {
+		return fmt.Errorf("invalid autosave delay: %w", err)
+	}
+
+	c.EditingMode = EditingMode(c.single(KeyEditingMode))
+	if !slices.Contains([]EditingMode{EditingDefault, EditingVim}, c.EditingMode) {
+		return fmt.Errorf("unknown editing mode %s", c.EditingMode)
+	}
+
+	c.ZenWidth, err = strconv.Atoi(c.single(KeyZenWidth))
+	if err != nil || c.ZenWidth <= 0 {
+		return fmt.Errorf("%s should be a positive number", KeyZenWidth)
+	}
+
+	c.ZenParent, err = strconv.ParseBool(c.single(KeyZenParent))
+	if err != nil {
+		return fmt.Errorf("%s should be true or false", KeyZenParent)
+	}
+
+	c.SyncRemote = c.single(KeySyncRemote)
+	c.SyncBranch = c.single(KeySyncBranch)
+	if c.SyncBranch == "" {
+		return fmt.Errorf("sync branch can't be empty")
+	}
+
+	c.APIToken = c.single(KeyAPIToken)
+
+	// an empty list can only be passed from env or flags as an empty string
+	c.NotifyCommand = slices.DeleteFunc(slices.Clone(c.values[KeyNotifyCommand]), func(arg string) bool {
+		return arg == ""
+	})
+
+	c.BackupDir = c.single(KeyBackupDir)
+	for key, target := range map[string]*int{
+		KeyBackupDaily:   &c.BackupDaily,
+		KeyBackupWeekly:  &c.BackupWeekly,
+		KeyBackupMonthly: &c.BackupMonthly,
+	} {
+		*target, err = strconv.Atoi(c.single(key))
+		if err != nil || *target < 0 {
+			return fmt.Errorf("%s should be a non-negative number", key)
+		}
+	}
+
+	for key, value := range c.values {
+		if action, ok := strings.CutPrefix(key, keymapPrefix); ok {
+			c.Keymap[action] = value
+		}
+	}
+
+	return c.parseTemplates()
+}
+
+func (c *Config) parseTemplates() error {
+	c.TemplatesDir = c.single(KeyTemplatesDir)
+
+	for key := range c.values {
+		if name, ok := strings.CutPrefix(key, templatePrefix); ok {
+			period, err := model.ParsePeriod(name)
+			if err != nil {
+				return fmt.Errorf("template: %w", err)
+			}
+			c.Templates[period] = c.single(key)
+		}
+	}
+
+	if c.TemplatesDir == "" {
+		return nil
+	}
+
+	for _, period := range model.Periods {
+		if _, ok := c.Templates[period]; ok {
+			continue
+		}
+
+		path := filepath.Join(os.ExpandEnv(c.TemplatesDir), strings.ToLower(model.PeriodName(period))+".md")
+		content, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+
+		c.Templates[period] = string(content)
+	}
+
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown week start %s", name)
+}
+
+// Source returns where the effective value of the key came from
+func (c *Config) Source(key string) Source {
+	return c.sources[key]
+}
+
+// Keys returns all keys with effective values sorted, keymap entries included
+func (c *Config) Keys() []string {
+	return slices.Sorted(maps.Keys(c.values))
+}
+
+// Value returns the raw effective value
+func (c *Config) Value(key string) []string {
+	return c.values[key]
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"flag"
+	"github.com/nvbn/termonizer/internal/model"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestResolve_Defaults(t *testing.T) {
+	c, err := Resolve(Defaults())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !reflect.DeepEqual(c.Periods, model.Periods) {
+		t.Errorf("expected all periods, got %v", c.Periods)
+	}
+
+	if c.WeekStart != time.Monday {
+		t.Errorf("expected monday, got %v", c.WeekStart)
+	}
+
+	if c.Autosave != AutosaveImmediate {
+		t.Errorf("expected immediate autosave, got %v", c.Autosave)
+	}
+
+	if c.BackupDaily != 7 || c.BackupWeekly != 4 || c.BackupMonthly != 12 {
+		t.Errorf("expected 7/4/12 retention, got %d/%d/%d", c.BackupDaily, c.BackupWeekly, c.BackupMonthly)
+	}
+}
+
+func TestResolve_Precedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(`
+db = "from-file.db"
+theme = "light"
+periods = ["day", "week"]
+week_start = "sunday"
+
+[keymap]
+focus_left = ["Alt+Rune[h]"]
+
+[themes.mine]
+base = "dark"
+`), 0644); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	file, err := FromFile(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	settings := FromSettings([]model.Setting{
+		{ID: KeyTheme, Value: "solarized"},
+		{ID: KeyAutosave, Value: "blur"},
+		{ID: KeyDB, Value: "ignored.db"},
+	})
+
+	env := FromEnv(func(name string) (string, bool) {
+		if name == "TERMONIZER_THEME" {
+			return "high-contrast", true
+		}
+		return "", false
+	})
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("db", "", "")
+	flags.String("week-start", "", "")
+	if err := flags.Parse([]string{"-db", "from-flag.db"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	c, err := Resolve(Defaults(), settings, file, env, FromFlags(flags))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expectedValues := map[string]struct {
+		value  any
+		actual any
+		source Source
+	}{
+		KeyDB:        {"from-flag.db", c.DB, SourceFlag},
+		KeyTheme:     {"high-contrast", c.Theme, SourceEnv},
+		KeyWeekStart: {time.Sunday, c.WeekStart, SourceFile},
+		KeyAutosave:  {AutosaveBlur, c.Autosave, SourceSettings},
+		KeyPeriods:   {[]model.Period{model.Week, model.Day}, c.Periods, SourceFile},
+	}
+
+	for key, expected := range expectedValues {
+		t.Run(key, func(t *testing.T) {
+			if !reflect.DeepEqual(expected.value, expected.actual) {
+				t.Errorf("expected %v, got %v", expected.value, expected.actual)
+			}
+
+			if c.Source(key) != expected.source {
+				t.Errorf("expected source %v, got %v", expected.source, c.Source(key))
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(c.Keymap["focus_left"], []string{"Alt+Rune[h]"}) {
+		t.Errorf("unexpected keymap %v", c.Keymap)
+	}
+
+	if _, ok := c.Themes["mine"]; !ok {
+		t.Errorf("expected user theme, got %v", c.Themes)
+	}
+}
+
+func TestResolve_Invalid(t *testing.T) {
+	invalid := map[string]map[string][]string{
+		"unknown period":    {KeyPeriods: {"decade"}},
+		"duplicated period": {KeyPeriods: {"day", "day"}},
+		"no periods":        {KeyPeriods: {}},
+		"week start":        {KeyWeekStart: {"someday"}},
+		"autosave":          {KeyAutosave: {"never"}},
+		"autosave delay":    {KeyAutosaveDelay: {"soon"}},
+		"editing mode":      {KeyEditingMode: {"emacs"}},
+		"zen width":         {KeyZenWidth: {"0"}},
+		"zen parent":        {KeyZenParent: {"sometimes"}},
+		"backup retention":  {KeyBackupWeekly: {"-1"}},
+		"sync branch":       {KeySyncBranch: {""}},
+	}
+
+	for name, values := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Resolve(Defaults(), Layer{Source: SourceFile, Values: values}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestResolve_NotifyCommand(t *testing.T) {
+	inputsExpecteds := map[string]struct {
+		value    string
+		expected []string
+	}{
+		"command":  {"notify-send, -u, critical", []string{"notify-send", "-u", "critical"}},
+		"disabled": {"", []string{}},
+	}
+
+	for name, inputExpected := range inputsExpecteds {
+		t.Run(name, func(t *testing.T) {
+			env := FromEnv(func(name string) (string, bool) {
+				return inputExpected.value, name == "TERMONIZER_NOTIFY_COMMAND"
+			})
+
+			c, err := Resolve(Defaults(), env)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if !reflect.DeepEqual(c.NotifyCommand, inputExpected.expected) {
+				t.Errorf("expected %v, got %v", inputExpected.expected, c.NotifyCommand)
+			}
+		})
+	}
+}
+
+func TestFromFile_Missing(t *testing.T) {
+	layer, err := FromFile(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(layer.Values) != 0 {
+		t.Errorf("expected empty layer, got %v", layer.Values)
+	}
+}
+
+func TestFromFile_UnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(`unknown = "value"`), 0644); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := FromFile(path); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestResolve_Templates(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"day.md": "from file", "week.md": "ignored"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	c, err := Resolve(Defaults(), Layer{
+		Source: SourceFile,
+		Values: map[string][]string{
+			KeyTemplatesDir: {dir},
+			"template.week": {"explicit"},
+		},
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := map[model.Period]string{
+		model.Day:  "from file",
+		model.Week: "explicit",
+	}
+	if !reflect.DeepEqual(c.Templates, expected) {
+		t.Errorf("expected %v, got %v", expected, c.Templates)
+	}
+}
+
+func TestThemesFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "themes.toml")
+	if err := os.WriteFile(path, []byte("[themes.mine]\nbase = \"dark\"\n\n[themes.other]\nbase = \"light\"\n"), 0644); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	themes, err := ThemesFromFile(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	file := Layer{Source: SourceFile, Themes: map[string]map[string]string{"mine": {"base": "solarized"}}}
+	c, err := Resolve(Defaults(), themes, file)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := map[string]map[string]string{"mine": {"base": "solarized"}, "other": {"base": "light"}}
+	if !reflect.DeepEqual(c.Themes, expected) {
+		t.Errorf("expected %v, got %v", expected, c.Themes)
+	}
+
+	if missing, err := ThemesFromFile(filepath.Join(t.TempDir(), "missing.toml")); err != nil || len(missing.Themes) != 0 {
+		t.Errorf("expected empty layer, got %v %v", missing, err)
+	}
+}
@@ -0,0 +1,269 @@
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// temporary files of atomic writes shouldn't be committed
+const gitignore = ".*.md.*\n"
+
+// Repo syncs a directory of goals with a git remote using the git cli
+type Repo struct {
+	dir    string
+	remote string
+	branch string
+}
+
+type Result struct {
+	Committed bool
+	Merged    bool
+	Pushed    bool
+	// Conflicts are goal files committed with conflict markers, they're resolved by editing the goal
+	Conflicts []string
+	// Overridden are other conflicting files where the local version is kept, like settings.toml
+	Overridden []string
+	// Deleted are goal files edited on one side and deleted on the other, the edited version is kept
+	Deleted []string
+}
+
+func New(dir string, remote string, branch string) *Repo {
+	return &Repo{
+		dir:    dir,
+		remote: remote,
+		branch: branch,
+	}
+}
+
+func (r *Repo) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	// a machine without configured identity still should be able to sync
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+envOr("GIT_AUTHOR_NAME", "termonizer"),
+		"GIT_AUTHOR_EMAIL="+envOr("GIT_AUTHOR_EMAIL", "termonizer@localhost"),
+		"GIT_COMMITTER_NAME="+envOr("GIT_COMMITTER_NAME", "termonizer"),
+		"GIT_COMMITTER_EMAIL="+envOr("GIT_COMMITTER_EMAIL", "termonizer@localhost"),
+	)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+func envOr(name string, fallback string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+
+	return fallback
+}
+
+func (r *Repo) init(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(r.dir, ".git")); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to check repository: %w", err)
+	}
+
+	if _, err := r.git(ctx, "init", "-b", r.branch); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(r.dir, ".gitignore"), []byte(gitignore), 0600)
+}
+
+func (r *Repo) commit(ctx context.Context, message string) (bool, error) {
+	if _, err := r.git(ctx, "add", "-A"); err != nil {
+		return false, err
+	}
+
+	status, err := r.git(ctx, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+
+	if status == "" {
+		return false, nil
+	}
+
+	if _, err := r.git(ctx, "commit", "-m", message); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *Repo) setRemote(ctx context.Context) error {
+	current, err := r.git(ctx, "remote", "get-url", "origin")
+	if err != nil {
+		_, err = r.git(ctx, "remote", "add", "origin", r.remote)
+		return err
+	}
+
+	if strings.TrimSpace(current) != r.remote {
+		_, err = r.git(ctx, "remote", "set-url", "origin", r.remote)
+	}
+
+	return err
+}
+
+// merge merges the remote branch, conflicting goals are committed with conflict markers,
+// so the sync never stops halfway and conflicts are visible in goals
+func (r *Repo) merge(ctx context.Context, result *Result) error {
+	remoteBranch := "origin/" + r.branch
+	if _, err := r.git(ctx, "rev-parse", "--verify", "--quiet", remoteBranch); err != nil {
+		// nothing was pushed yet
+		return nil
+	}
+
+	// every machine could start with its own history
+	_, mergeErr := r.git(ctx, "merge", "--no-edit", "--allow-unrelated-histories", remoteBranch)
+	if mergeErr == nil {
+		result.Merged = true
+		return nil
+	}
+
+	unmerged, err := r.unmerged(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(unmerged) == 0 {
+		return mergeErr
+	}
+
+	// the repository shouldn't stay in the middle of a merge when a conflict can't be resolved
+	if err := r.resolve(ctx, unmerged, result); err != nil {
+		if _, abortErr := r.git(ctx, "merge", "--abort"); abortErr != nil {
+			return errors.Join(err, abortErr)
+		}
+		return err
+	}
+
+	if _, err := r.git(ctx, "commit", "--no-edit"); err != nil {
+		return err
+	}
+
+	result.Merged = true
+	return nil
+}
+
+// unmerged returns paths with their porcelain status, like UU for both modified or DU for deleted by us
+func (r *Repo) unmerged(ctx context.Context) (map[string]string, error) {
+	status, err := r.git(ctx, "status", "--porcelain", "-z", "--untracked-files=no")
+	if err != nil {
+		return nil, err
+	}
+
+	unmerged := make(map[string]string)
+	entries := strings.Split(strings.TrimRight(status, "\x00"), "\x00")
+	for n := 0; n < len(entries); n++ {
+		entry := entries[n]
+		if len(entry) < 4 {
+			continue
+		}
+
+		code, path := entry[:2], entry[3:]
+		// the original path of a rename is the next entry
+		if code[0] == 'R' || code[0] == 'C' {
+			n++
+		}
+
+		if code == "DD" || code == "AA" || strings.Contains(code, "U") {
+			unmerged[path] = code
+		}
+	}
+
+	return unmerged, nil
+}
+
+// resolve keeps conflict markers in goals, goals edited on one side and deleted on the other are kept,
+// other files are resolved with the local version
+func (r *Repo) resolve(ctx context.Context, unmerged map[string]string, result *Result) error {
+	for _, path := range slices.Sorted(maps.Keys(unmerged)) {
+		code := unmerged[path]
+		oursDeleted := code == "DU" || code == "UA" || code == "DD"
+
+		switch {
+		case code == "DD":
+			if _, err := r.git(ctx, "rm", "--quiet", "--", path); err != nil {
+				return err
+			}
+		case strings.HasSuffix(path, ".md") && (code == "UU" || code == "AA"):
+			result.Conflicts = append(result.Conflicts, path)
+		case strings.HasSuffix(path, ".md"):
+			// the work tree has the version that wasn't deleted
+			result.Deleted = append(result.Deleted, path)
+		case oursDeleted:
+			if _, err := r.git(ctx, "rm", "--quiet", "--", path); err != nil {
+				return err
+			}
+			result.Overridden = append(result.Overridden, path)
+		default:
+			if _, err := r.git(ctx, "checkout", "--ours", "--", path); err != nil {
+				return err
+			}
+			result.Overridden = append(result.Overridden, path)
+		}
+	}
+
+	_, err := r.git(ctx, "add", "-A")
+	return err
+}
+
+// Sync commits local changes, merges the remote branch and pushes the result
+func (r *Repo) Sync(ctx context.Context, message string) (Result, error) {
+	result := Result{}
+
+	if err := r.init(ctx); err != nil {
+		return result, err
+	}
+
+	committed, err := r.commit(ctx, message)
+	if err != nil {
+		return result, err
+	}
+	result.Committed = committed
+
+	if r.remote == "" {
+		return result, nil
+	}
+
+	if err := r.setRemote(ctx); err != nil {
+		return result, err
+	}
+
+	if _, err := r.git(ctx, "fetch", "origin"); err != nil {
+		return result, err
+	}
+
+	if err := r.merge(ctx, &result); err != nil {
+		return result, err
+	}
+
+	if _, err := r.git(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// nothing to push in an empty repository
+		return result, nil
+	}
+
+	if _, err := r.git(ctx, "push", "origin", "HEAD:"+r.branch); err != nil {
+		return result, err
+	}
+	result.Pushed = true
+
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package gitsync
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeGoal(t *testing.T, dir string, path string, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0700); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0600); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func readGoal(t *testing.T, dir string, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filepath.Join(dir, path))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	return string(content)
+}
+
+func TestRepo_Sync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	ctx := t.Context()
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--bare", "-b", "main", remote).CombinedOutput(); err != nil {
+		t.Fatalf("failed to init remote: %v %s", err, out)
+	}
+
+	laptop := t.TempDir()
+	desktop := t.TempDir()
+
+	writeGoal(t, laptop, "day/2024-12-10.md", "* laptop")
+	writeGoal(t, laptop, "week/2024-W50.md", "* week\n\n* shared\n")
+	if _, err := New(laptop, remote, "main").Sync(ctx, "laptop"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// desktop starts with its own notes and history
+	writeGoal(t, desktop, "day/2024-12-09.md", "* desktop")
+	result, err := New(desktop, remote, "main").Sync(ctx, "desktop")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !result.Committed || !result.Merged || !result.Pushed || len(result.Conflicts) != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if readGoal(t, desktop, "day/2024-12-10.md") != "* laptop" {
+		t.Error("expected goal from laptop")
+	}
+
+	// non-conflicting edits of different goals and the same goal are merged, the same line conflicts
+	writeGoal(t, laptop, "week/2024-W50.md", "* week from laptop\n\n* shared\n")
+	writeGoal(t, laptop, "day/2024-12-10.md", "* laptop edited")
+	if _, err := New(laptop, remote, "main").Sync(ctx, "laptop"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	writeGoal(t, desktop, "week/2024-W50.md", "* week\n\n* shared\n* from desktop\n")
+	writeGoal(t, desktop, "day/2024-12-10.md", "* desktop edited")
+	result, err = New(desktop, remote, "main").Sync(ctx, "desktop")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if week := readGoal(t, desktop, "week/2024-W50.md"); week != "* week from laptop\n\n* shared\n* from desktop\n" {
+		t.Errorf("expected merged week, got %q", week)
+	}
+
+	if len(result.Conflicts) != 1 || result.Conflicts[0] != "day/2024-12-10.md" {
+		t.Errorf("expected conflict in the day, got %+v", result)
+	}
+
+	if day := readGoal(t, desktop, "day/2024-12-10.md"); !strings.Contains(day, "<<<<<<<") || !strings.Contains(day, "* laptop edited") {
+		t.Errorf("expected conflict markers, got %q", day)
+	}
+
+	// the laptop gets the merge with the conflict
+	if _, err := New(laptop, remote, "main").Sync(ctx, "laptop"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if readGoal(t, laptop, "day/2024-12-10.md") != readGoal(t, desktop, "day/2024-12-10.md") {
+		t.Error("expected the same content after sync")
+	}
+}
+
+func TestRepo_Sync_DeleteConflicts(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	ctx := t.Context()
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	if out, err := exec.Command("git", "init", "--bare", "-b", "main", remote).CombinedOutput(); err != nil {
+		t.Fatalf("failed to init remote: %v %s", err, out)
+	}
+
+	laptop := t.TempDir()
+	desktop := t.TempDir()
+
+	writeGoal(t, laptop, "day/2024-12-10.md", "* day")
+	writeGoal(t, laptop, "day/2024-12-11.md", "* another day")
+	writeGoal(t, laptop, "settings.toml", "theme = \"dark\"\n")
+	for _, dir := range []string{laptop, desktop} {
+		if _, err := New(dir, remote, "main").Sync(ctx, "initial"); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	// edited on the laptop and deleted on the desktop, and the other way around
+	writeGoal(t, laptop, "day/2024-12-10.md", "* day edited")
+	writeGoal(t, laptop, "settings.toml", "theme = \"light\"\n")
+	if err := os.Remove(filepath.Join(laptop, "day/2024-12-11.md")); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	if _, err := New(laptop, remote, "main").Sync(ctx, "laptop"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, path := range []string{"day/2024-12-10.md", "settings.toml"} {
+		if err := os.Remove(filepath.Join(desktop, path)); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+	writeGoal(t, desktop, "day/2024-12-11.md", "* another day edited")
+
+	result, err := New(desktop, remote, "main").Sync(ctx, "desktop")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !result.Merged || !result.Pushed {
+		t.Errorf("expected the merge to be pushed, got %+v", result)
+	}
+
+	if !reflect.DeepEqual(result.Deleted, []string{"day/2024-12-10.md", "day/2024-12-11.md"}) {
+		t.Errorf("expected both goals to be reported, got %+v", result)
+	}
+
+	if !reflect.DeepEqual(result.Overridden, []string{"settings.toml"}) {
+		t.Errorf("expected settings to be deleted as locally, got %+v", result)
+	}
+
+	if readGoal(t, desktop, "day/2024-12-10.md") != "* day edited" || readGoal(t, desktop, "day/2024-12-11.md") != "* another day edited" {
+		t.Error("expected edited versions to be kept")
+	}
+
+	if _, err := os.Stat(filepath.Join(desktop, "settings.toml")); !os.IsNotExist(err) {
+		t.Errorf("expected settings to stay deleted, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(desktop, ".git", "MERGE_HEAD")); !os.IsNotExist(err) {
+		t.Errorf("expected the merge to be finished, got %v", err)
+	}
+}
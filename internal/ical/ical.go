@@ -0,0 +1,127 @@
+package ical
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	// lines longer than this are folded, as required by rfc 5545
+	maxLineLength = 75
+)
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func uid(goal model.Goal) string {
+	return goal.ID + "@termonizer"
+}
+
+// itemUID depends on the text of the item, so it stays the same when other items are added or reordered,
+// an edited item is a new todo, repeated texts are told apart by the occurrence
+func itemUID(goal model.Goal, text string, occurrence int) string {
+	sum := sha256.Sum256([]byte(goal.ID + "\x00" + text))
+	uid := goal.ID + "-" + hex.EncodeToString(sum[:8])
+	if occurrence > 0 {
+		uid = fmt.Sprintf("%s-%d", uid, occurrence+1)
+	}
+
+	return uid + "@termonizer"
+}
+
+type writer struct {
+	out io.Writer
+	err error
+}
+
+// line writes a content line folded to 75 octets without splitting utf-8 sequences
+func (w *writer) line(name string, value string) {
+	if w.err != nil {
+		return
+	}
+
+	rest := name + ":" + value
+	prefix := ""
+	for {
+		limit := maxLineLength - len(prefix)
+		if len(rest) <= limit {
+			_, w.err = io.WriteString(w.out, prefix+rest+"\r\n")
+			return
+		}
+
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(rest[cut]) {
+			cut--
+		}
+
+		if _, w.err = io.WriteString(w.out, prefix+rest[:cut]+"\r\n"); w.err != nil {
+			return
+		}
+		rest = rest[cut:]
+		prefix = " "
+	}
+}
+
+func (w *writer) date(name string, date time.Time) {
+	w.line(name+";VALUE=DATE", date.Format(dateFormat))
+}
+
+// Write exports goals as all-day events spanning their periods and checklist items as todos related to them,
+// empty goals are skipped
+func Write(out io.Writer, goals []model.Goal, now time.Time) error {
+	w := &writer{out: out}
+	stamp := now.UTC().Format(dateTimeFormat)
+
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//nvbn//termonizer//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("X-WR-CALNAME", "termonizer")
+
+	for _, goal := range goals {
+		if strings.TrimSpace(goal.Content) == "" {
+			continue
+		}
+
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", uid(goal))
+		w.line("DTSTAMP", stamp)
+		w.line("LAST-MODIFIED", goal.Updated.UTC().Format(dateTimeFormat))
+		w.date("DTSTART", goal.Start)
+		w.date("DTEND", goal.End())
+		w.line("SUMMARY", textEscaper.Replace(model.PeriodName(goal.Period)+" "+goal.FormatStart()))
+		w.line("DESCRIPTION", textEscaper.Replace(goal.Content))
+		w.line("TRANSP", "TRANSPARENT")
+		w.line("END", "VEVENT")
+
+		occurrences := make(map[string]int)
+		for _, item := range goal.Checklist() {
+			status := "NEEDS-ACTION"
+			if item.Done {
+				status = "COMPLETED"
+			}
+
+			w.line("BEGIN", "VTODO")
+			w.line("UID", itemUID(goal, item.Text, occurrences[item.Text]))
+			occurrences[item.Text]++
+			w.line("DTSTAMP", stamp)
+			// the last day of the period, without start as due should be after it
+			w.date("DUE", goal.End().AddDate(0, 0, -1))
+			w.line("SUMMARY", textEscaper.Replace(item.Text))
+			w.line("STATUS", status)
+			w.line("RELATED-TO", uid(goal))
+			w.line("END", "VTODO")
+		}
+	}
+
+	w.line("END", "VCALENDAR")
+
+	return w.err
+}
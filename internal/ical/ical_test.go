@@ -0,0 +1,119 @@
+package ical
+
+import (
+	"bytes"
+	"github.com/nvbn/termonizer/internal/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)
+	now := time.Date(2024, 12, 10, 10, 0, 0, 0, time.UTC)
+
+	goals := []model.Goal{
+		{ID: "week", Period: model.Week, Start: start, Updated: now, Content: "* [ ] write report, draft\n* [x] call Bob"},
+		{ID: "empty", Period: model.Day, Start: start, Updated: now, Content: ""},
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, goals, now); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//nvbn//termonizer//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:termonizer",
+		"BEGIN:VEVENT",
+		"UID:week@termonizer",
+		"DTSTAMP:20241210T100000Z",
+		"LAST-MODIFIED:20241210T100000Z",
+		"DTSTART;VALUE=DATE:20241209",
+		"DTEND;VALUE=DATE:20241216",
+		"SUMMARY:Week 2024-12-09 W50",
+		`DESCRIPTION:* [ ] write report\, draft\n* [x] call Bob`,
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"BEGIN:VTODO",
+		"UID:week-8db1bfe8da8b3e5a@termonizer",
+		"DTSTAMP:20241210T100000Z",
+		"DUE;VALUE=DATE:20241215",
+		`SUMMARY:write report\, draft`,
+		"STATUS:NEEDS-ACTION",
+		"RELATED-TO:week@termonizer",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:week-0638099ce50b2ae7@termonizer",
+		"DTSTAMP:20241210T100000Z",
+		"DUE;VALUE=DATE:20241215",
+		"SUMMARY:call Bob",
+		"STATUS:COMPLETED",
+		"RELATED-TO:week@termonizer",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestItemUID_Stable(t *testing.T) {
+	before := model.Goal{ID: "week", Content: "* [ ] a\n* [ ] b"}
+	after := model.Goal{ID: "week", Content: "* [ ] new\n* [ ] b\n* [x] a\n* [ ] a"}
+
+	uids := func(goal model.Goal) map[string]string {
+		var out bytes.Buffer
+		if err := Write(&out, []model.Goal{goal}, time.Now()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		summaryToUID := make(map[string]string)
+		uid := ""
+		for _, line := range strings.Split(out.String(), "\r\n") {
+			if value, ok := strings.CutPrefix(line, "UID:"); ok {
+				uid = value
+			} else if value, ok := strings.CutPrefix(line, "SUMMARY:"); ok && summaryToUID[value] == "" {
+				summaryToUID[value] = uid
+			}
+		}
+		return summaryToUID
+	}
+
+	beforeUIDs, afterUIDs := uids(before), uids(after)
+	for _, text := range []string{"a", "b"} {
+		if beforeUIDs[text] != afterUIDs[text] {
+			t.Errorf("expected the same uid of %q, got %s and %s", text, beforeUIDs[text], afterUIDs[text])
+		}
+	}
+
+	if itemUID(after, "a", 0) == itemUID(after, "a", 1) {
+		t.Error("expected repeated items to have different uids")
+	}
+}
+
+func TestWriter_Fold(t *testing.T) {
+	var out bytes.Buffer
+	w := &writer{out: &out}
+	w.line("DESCRIPTION", strings.Repeat("ж", 50))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\r\n"), "\r\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", lines)
+	}
+
+	for _, line := range lines {
+		if len(line) > maxLineLength || !strings.HasPrefix(lines[1], " ") {
+			t.Errorf("invalid folded line %q", line)
+		}
+	}
+
+	if unfolded := lines[0] + strings.TrimPrefix(lines[1], " "); unfolded != "DESCRIPTION:"+strings.Repeat("ж", 50) {
+		t.Errorf("unexpected unfolded line %q", unfolded)
+	}
+}
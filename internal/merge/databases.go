@@ -0,0 +1,253 @@
+package merge
+
+import (
+	"context"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/storage"
+	"time"
+)
+
+const (
+	oursLabel   = "this database"
+	theirsLabel = "other database"
+)
+
+type Result struct {
+	// Added are goals copied from one database to another
+	Added int
+	// Updated are goals changed only in one database
+	Updated int
+	// Trashed are goals deleted in one database and trashed in another
+	Trashed int
+	// Merged are goals changed in both databases
+	Merged int
+	// Conflicts are merged goals with conflict markers
+	Conflicts []model.Goal
+}
+
+type pair struct {
+	ours   *model.Goal
+	theirs *model.Goal
+}
+
+// pairGoals matches goals by id, goals created separately for the same period and start are matched too
+func pairGoals(ours []model.Goal, theirs []model.Goal) []pair {
+	pairs := make([]pair, 0, len(ours)+len(theirs))
+	paired := make(map[string]bool)
+
+	idToOurs := make(map[string]*model.Goal)
+	for n := range ours {
+		idToOurs[ours[n].ID] = &ours[n]
+	}
+
+	for n := range theirs {
+		if goal, ok := idToOurs[theirs[n].ID]; ok {
+			pairs = append(pairs, pair{ours: goal, theirs: &theirs[n]})
+			paired[goal.ID] = true
+		}
+	}
+
+	for n := range theirs {
+		if _, ok := idToOurs[theirs[n].ID]; ok {
+			continue
+		}
+
+		var match *model.Goal
+		for m := range ours {
+			if !paired[ours[m].ID] && ours[m].Period == theirs[n].Period && ours[m].CompareStart(theirs[n].Start) == 0 {
+				match = &ours[m]
+				break
+			}
+		}
+
+		if match != nil {
+			paired[match.ID] = true
+		}
+		pairs = append(pairs, pair{ours: match, theirs: &theirs[n]})
+	}
+
+	for n := range ours {
+		if !paired[ours[n].ID] {
+			pairs = append(pairs, pair{ours: &ours[n]})
+		}
+	}
+
+	return pairs
+}
+
+// commonBase returns content of the latest revision present in both histories
+func commonBase(ours []model.Revision, theirs []model.Revision) string {
+	for n := len(ours) - 1; n >= 0; n-- {
+		for _, revision := range theirs {
+			if revision.Updated.Equal(ours[n].Updated) && revision.Content == ours[n].Content {
+				return revision.Content
+			}
+		}
+	}
+
+	return ""
+}
+
+func trashedAt(ctx context.Context, db *storage.SQLite) (map[string]time.Time, error) {
+	trashed, err := db.ReadTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idToDeleted := make(map[string]time.Time)
+	for _, goal := range trashed {
+		idToDeleted[goal.ID] = goal.Deleted
+	}
+
+	return idToDeleted, nil
+}
+
+// copyGoal adds the goal missing in the database
+func copyGoal(ctx context.Context, from *storage.SQLite, to *storage.SQLite, goal model.Goal) error {
+	revisions, err := from.ReadRevisions(ctx, goal.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := to.ImportRevisions(ctx, revisions); err != nil {
+		return err
+	}
+
+	return to.UpdateGoal(ctx, goal)
+}
+
+// shareHistory pins revisions of both goals in both databases, so they're merge bases next time
+func shareHistory(ctx context.Context, ours *storage.SQLite, theirs *storage.SQLite, oursGoal model.Goal, theirsGoal model.Goal) (string, error) {
+	oursRevisions, err := ours.ReadRevisions(ctx, oursGoal.ID)
+	if err != nil {
+		return "", err
+	}
+
+	theirsRevisions, err := theirs.ReadRevisions(ctx, theirsGoal.ID)
+	if err != nil {
+		return "", err
+	}
+
+	base := commonBase(oursRevisions, theirsRevisions)
+
+	// goals created separately have different ids, their histories aren't related
+	if oursGoal.ID == theirsGoal.ID {
+		oursRevisions = append(oursRevisions, theirsRevisions...)
+		theirsRevisions = oursRevisions
+	}
+
+	if err := ours.ImportRevisions(ctx, oursRevisions); err != nil {
+		return "", err
+	}
+
+	if err := theirs.ImportRevisions(ctx, theirsRevisions); err != nil {
+		return "", err
+	}
+
+	return base, nil
+}
+
+// Databases reconciles two databases, both of them have the same goals afterwards. Goals changed in both are
+// merged line by line with the latest common revision as the base.
+func Databases(ctx context.Context, ours *storage.SQLite, theirs *storage.SQLite, now time.Time) (Result, error) {
+	result := Result{}
+
+	oursGoals, err := ours.ReadAllGoals(ctx)
+	if err != nil {
+		return result, fmt.Errorf("unable to read goals: %w", err)
+	}
+
+	theirsGoals, err := theirs.ReadAllGoals(ctx)
+	if err != nil {
+		return result, fmt.Errorf("unable to read other goals: %w", err)
+	}
+
+	oursTrashed, err := trashedAt(ctx, ours)
+	if err != nil {
+		return result, fmt.Errorf("unable to read trash: %w", err)
+	}
+
+	theirsTrashed, err := trashedAt(ctx, theirs)
+	if err != nil {
+		return result, fmt.Errorf("unable to read other trash: %w", err)
+	}
+
+	for _, p := range pairGoals(oursGoals, theirsGoals) {
+		if p.ours == nil || p.theirs == nil {
+			from, to, goal, trashed := theirs, ours, p.theirs, oursTrashed
+			if p.theirs == nil {
+				from, to, goal, trashed = ours, theirs, p.ours, theirsTrashed
+			}
+
+			if goal.Content == "" {
+				continue
+			}
+
+			// deleted in another database after the last change
+			if deleted, ok := trashed[goal.ID]; ok && !goal.Updated.After(deleted) {
+				if err := from.TrashGoal(ctx, goal.ID, deleted); err != nil {
+					return result, fmt.Errorf("unable to trash goal: %w", err)
+				}
+
+				result.Trashed += 1
+				continue
+			}
+
+			if err := copyGoal(ctx, from, to, *goal); err != nil {
+				return result, fmt.Errorf("unable to copy goal: %w", err)
+			}
+
+			result.Added += 1
+			continue
+		}
+
+		base, err := shareHistory(ctx, ours, theirs, *p.ours, *p.theirs)
+		if err != nil {
+			return result, fmt.Errorf("unable to share history: %w", err)
+		}
+
+		if p.ours.Content == p.theirs.Content {
+			continue
+		}
+
+		merged, conflict := Lines(base, p.ours.Content, p.theirs.Content, oursLabel, theirsLabel)
+
+		oursGoal, theirsGoal := *p.ours, *p.theirs
+		switch merged {
+		case p.theirs.Content:
+			// changed only in the other database
+			oursGoal.Content, oursGoal.Updated = theirsGoal.Content, theirsGoal.Updated
+			result.Updated += 1
+		case p.ours.Content:
+			theirsGoal.Content, theirsGoal.Updated = oursGoal.Content, oursGoal.Updated
+			result.Updated += 1
+		default:
+			oursGoal.Content, oursGoal.Updated = merged, now
+			theirsGoal.Content, theirsGoal.Updated = merged, now
+			result.Merged += 1
+			if conflict {
+				result.Conflicts = append(result.Conflicts, oursGoal)
+			}
+		}
+
+		if err := ours.UpdateGoal(ctx, oursGoal); err != nil {
+			return result, fmt.Errorf("unable to update goal: %w", err)
+		}
+
+		if err := theirs.UpdateGoal(ctx, theirsGoal); err != nil {
+			return result, fmt.Errorf("unable to update other goal: %w", err)
+		}
+
+		// the merge result is the base for the next merge
+		if err := ours.ImportRevisions(ctx, []model.Revision{{GoalID: oursGoal.ID, Content: oursGoal.Content, Updated: oursGoal.Updated}}); err != nil {
+			return result, fmt.Errorf("unable to save revision: %w", err)
+		}
+
+		if err := theirs.ImportRevisions(ctx, []model.Revision{{GoalID: theirsGoal.ID, Content: theirsGoal.Content, Updated: theirsGoal.Updated}}); err != nil {
+			return result, fmt.Errorf("unable to save other revision: %w", err)
+		}
+	}
+
+	return result, nil
+}
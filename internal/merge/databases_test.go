@@ -0,0 +1,173 @@
+package merge
+
+import (
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDatabase(t *testing.T) *storage.SQLite {
+	db, err := storage.NewSQLite(t.Context(), ":memory:")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func readContent(t *testing.T, db *storage.SQLite, id string) string {
+	goals, err := db.ReadAllGoals(t.Context())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, goal := range goals {
+		if goal.ID == id {
+			return goal.Content
+		}
+	}
+
+	return ""
+}
+
+func update(t *testing.T, db *storage.SQLite, goal model.Goal, content string, updated time.Time) {
+	goal.Content = content
+	goal.Updated = updated
+	if err := db.UpdateGoal(t.Context(), goal); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+}
+
+func TestDatabases(t *testing.T) {
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC)
+	created := start.Add(time.Hour)
+	changed := created.Add(time.Hour)
+	now := changed.Add(time.Hour)
+
+	goal := model.Goal{ID: "goal", Period: model.Day, Start: start}
+	base := "* first\n* second\n* third"
+
+	inputsExpecteds := []struct {
+		name      string
+		ours      string
+		theirs    string
+		expected  string
+		conflicts int
+	}{
+		{"unchanged", base, base, base, 0},
+		{"changed only in other", base, "* first\n* second\n* third\n* fourth", "* first\n* second\n* third\n* fourth", 0},
+		{"changed only in this", "* first", base, "* first", 0},
+		{"different lines", "* first!\n* second\n* third", "* first\n* second\n* third!", "* first!\n* second\n* third!", 0},
+		{"same line", "* first\n* second?\n* third", "* first\n* second!\n* third", "", 1},
+	}
+
+	for _, inputsExpected := range inputsExpecteds {
+		t.Run(inputsExpected.name, func(t *testing.T) {
+			ctx := t.Context()
+			ours, theirs := newDatabase(t), newDatabase(t)
+
+			update(t, ours, goal, base, created)
+			update(t, theirs, goal, base, created)
+			if inputsExpected.ours != base {
+				update(t, ours, goal, inputsExpected.ours, changed)
+			}
+			if inputsExpected.theirs != base {
+				update(t, theirs, goal, inputsExpected.theirs, changed)
+			}
+
+			result, err := Databases(ctx, ours, theirs, now)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if len(result.Conflicts) != inputsExpected.conflicts {
+				t.Errorf("expected %d conflicts, got %v", inputsExpected.conflicts, result.Conflicts)
+			}
+
+			oursContent, theirsContent := readContent(t, ours, goal.ID), readContent(t, theirs, goal.ID)
+			if oursContent != theirsContent {
+				t.Errorf("expected the same content, got %q and %q", oursContent, theirsContent)
+			}
+
+			if inputsExpected.conflicts > 0 {
+				if !strings.Contains(oursContent, "<<<<<<< "+oursLabel) {
+					t.Errorf("expected conflict markers, got %q", oursContent)
+				}
+			} else if oursContent != inputsExpected.expected {
+				t.Errorf("expected %q, got %q", inputsExpected.expected, oursContent)
+			}
+		})
+	}
+}
+
+func TestDatabases_Twice(t *testing.T) {
+	ctx := t.Context()
+	ours, theirs := newDatabase(t), newDatabase(t)
+
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC)
+	goal := model.Goal{ID: "goal", Period: model.Day, Start: start}
+
+	update(t, ours, goal, "* first", start)
+	update(t, theirs, goal, "* second", start)
+
+	if _, err := Databases(ctx, ours, theirs, start.Add(time.Hour)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// the previous merge result is the base
+	update(t, theirs, goal, "* resolved", start.Add(2*time.Hour))
+
+	result, err := Databases(ctx, ours, theirs, start.Add(3*time.Hour))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(result.Conflicts) != 0 || result.Updated != 1 {
+		t.Errorf("expected fast-forward, got %+v", result)
+	}
+
+	if content := readContent(t, ours, goal.ID); content != "* resolved" {
+		t.Errorf("expected resolved content, got %q", content)
+	}
+}
+
+func TestDatabases_Missing(t *testing.T) {
+	ctx := t.Context()
+	ours, theirs := newDatabase(t), newDatabase(t)
+
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC)
+	added := model.Goal{ID: "added", Period: model.Day, Start: start}
+	deleted := model.Goal{ID: "deleted", Period: model.Week, Start: start}
+
+	update(t, theirs, added, "* added", start)
+	update(t, ours, deleted, "* deleted", start)
+	update(t, theirs, deleted, "* deleted", start)
+
+	if err := theirs.TrashGoal(ctx, deleted.ID, start.Add(time.Hour)); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	result, err := Databases(ctx, ours, theirs, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if result.Added != 1 || result.Trashed != 1 {
+		t.Errorf("expected 1 added and 1 trashed goal, got %+v", result)
+	}
+
+	if content := readContent(t, ours, added.ID); content != "* added" {
+		t.Errorf("expected added goal, got %q", content)
+	}
+
+	if content := readContent(t, theirs, deleted.ID); content != "" {
+		t.Errorf("expected deleted goal to stay deleted, got %q", content)
+	}
+
+	if content := readContent(t, ours, deleted.ID); content != "" {
+		t.Errorf("expected deleted goal to be trashed, got %q", content)
+	}
+}
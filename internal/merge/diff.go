@@ -0,0 +1,59 @@
+package merge
+
+import (
+	"strings"
+)
+
+type DiffKind int
+
+const (
+	DiffSame DiffKind = iota
+	DiffRemoved
+	DiffAdded
+	// DiffPadding aligns the side with fewer changed lines
+	DiffPadding
+)
+
+// DiffRow is a line of both sides aligned for a side-by-side view
+type DiffRow struct {
+	Old     string
+	New     string
+	OldKind DiffKind
+	NewKind DiffKind
+}
+
+// Diff aligns lines of two texts by their longest common subsequence, changed lines are paired in the same rows
+func Diff(old string, new string) []DiffRow {
+	oldLines := strings.Split(old, "\n")
+	newLines := strings.Split(new, "\n")
+	matches := lcsMatches(oldLines, newLines)
+
+	rows := make([]DiffRow, 0, max(len(oldLines), len(newLines)))
+	addChanged := func(removed []string, added []string) {
+		for n := range max(len(removed), len(added)) {
+			row := DiffRow{OldKind: DiffPadding, NewKind: DiffPadding}
+			if n < len(removed) {
+				row.Old, row.OldKind = removed[n], DiffRemoved
+			}
+			if n < len(added) {
+				row.New, row.NewKind = added[n], DiffAdded
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	oldStart, newStart := 0, 0
+	for i, j := range matches {
+		if j == -1 {
+			continue
+		}
+
+		addChanged(oldLines[oldStart:i], newLines[newStart:j])
+		rows = append(rows, DiffRow{Old: oldLines[i], New: newLines[j]})
+		oldStart, newStart = i+1, j+1
+	}
+
+	addChanged(oldLines[oldStart:], newLines[newStart:])
+
+	return rows
+}
@@ -0,0 +1,40 @@
+package merge
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	inputsExpecteds := []struct {
+		name     string
+		old      string
+		new      string
+		expected []DiffRow
+	}{
+		{"same", "a\nb", "a\nb", []DiffRow{{Old: "a", New: "a"}, {Old: "b", New: "b"}}},
+		{"added", "a", "a\nb", []DiffRow{
+			{Old: "a", New: "a"},
+			{OldKind: DiffPadding, New: "b", NewKind: DiffAdded},
+		}},
+		{"removed", "a\nb\nc", "a\nc", []DiffRow{
+			{Old: "a", New: "a"},
+			{Old: "b", OldKind: DiffRemoved, NewKind: DiffPadding},
+			{Old: "c", New: "c"},
+		}},
+		{"changed", "* [ ] a\n* [ ] b\nc", "* [x] a\nc", []DiffRow{
+			{Old: "* [ ] a", OldKind: DiffRemoved, New: "* [x] a", NewKind: DiffAdded},
+			{Old: "* [ ] b", OldKind: DiffRemoved, NewKind: DiffPadding},
+			{Old: "c", New: "c"},
+		}},
+		{"empty", "", "a", []DiffRow{{Old: "", OldKind: DiffRemoved, New: "a", NewKind: DiffAdded}}},
+	}
+
+	for _, tt := range inputsExpecteds {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := Diff(tt.old, tt.new); !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, actual)
+			}
+		})
+	}
+}
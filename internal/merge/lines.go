@@ -0,0 +1,100 @@
+package merge
+
+import (
+	"slices"
+	"strings"
+)
+
+// lcsMatches returns for every line of a the index of the matched line of b in their longest common subsequence,
+// or -1 for not matched lines
+func lcsMatches(a []string, b []string) []int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	matches := make([]int, len(a))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] == b[j] {
+			matches[i] = j
+			i++
+			j++
+		} else if lengths[i+1][j] >= lengths[i][j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return matches
+}
+
+// Lines is a line level three-way merge, changes of only one side are taken as is, the same changes on both
+// sides are taken once, and different changes of the same lines are written with conflict markers
+func Lines(base string, ours string, theirs string, oursLabel string, theirsLabel string) (string, bool) {
+	baseLines := strings.Split(base, "\n")
+	oursLines := strings.Split(ours, "\n")
+	theirsLines := strings.Split(theirs, "\n")
+
+	oursMatches := lcsMatches(baseLines, oursLines)
+	theirsMatches := lcsMatches(baseLines, theirsLines)
+
+	result := make([]string, 0, max(len(oursLines), len(theirsLines)))
+	conflict := false
+	baseStart, oursStart, theirsStart := 0, 0, 0
+	for {
+		// the next base line kept by both sides
+		next := -1
+		for n := baseStart; n < len(baseLines); n++ {
+			if oursMatches[n] != -1 && theirsMatches[n] != -1 {
+				next = n
+				break
+			}
+		}
+
+		baseEnd, oursEnd, theirsEnd := len(baseLines), len(oursLines), len(theirsLines)
+		if next != -1 {
+			baseEnd, oursEnd, theirsEnd = next, oursMatches[next], theirsMatches[next]
+		}
+
+		baseChunk := baseLines[baseStart:baseEnd]
+		oursChunk := oursLines[oursStart:oursEnd]
+		theirsChunk := theirsLines[theirsStart:theirsEnd]
+		switch {
+		case slices.Equal(oursChunk, theirsChunk), slices.Equal(theirsChunk, baseChunk):
+			result = append(result, oursChunk...)
+		case slices.Equal(oursChunk, baseChunk):
+			result = append(result, theirsChunk...)
+		default:
+			conflict = true
+			result = append(result, "<<<<<<< "+oursLabel)
+			result = append(result, oursChunk...)
+			result = append(result, "=======")
+			result = append(result, theirsChunk...)
+			result = append(result, ">>>>>>> "+theirsLabel)
+		}
+
+		if next == -1 {
+			break
+		}
+
+		result = append(result, baseLines[next])
+		baseStart, oursStart, theirsStart = next+1, oursMatches[next]+1, theirsMatches[next]+1
+	}
+
+	return strings.Join(result, "\n"), conflict
+}
@@ -0,0 +1,51 @@
+package merge
+
+import "testing"
+
+func TestLines(t *testing.T) {
+	type testData struct {
+		name     string
+		base     string
+		ours     string
+		theirs   string
+		expected string
+		conflict bool
+	}
+
+	inputsExpecteds := []testData{
+		{"unchanged", "* a\n* b\n", "* a\n* b\n", "* a\n* b\n", "* a\n* b\n", false},
+		{"only ours", "* a\n* b\n", "* a\n* b\n* c\n", "* a\n* b\n", "* a\n* b\n* c\n", false},
+		{"only theirs", "* a\n* b\n", "* a\n* b\n", "* a edited\n* b\n", "* a edited\n* b\n", false},
+		{"different lines", "* a\n\n* b\n", "* a ours\n\n* b\n", "* a\n\n* b theirs\n", "* a ours\n\n* b theirs\n", false},
+		{"same change", "* a\n", "* a done\n", "* a done\n", "* a done\n", false},
+		{
+			"same line",
+			"* a\n* b\n",
+			"* a ours\n* b\n",
+			"* a theirs\n* b\n",
+			"<<<<<<< ours\n* a ours\n=======\n* a theirs\n>>>>>>> theirs\n* b\n",
+			true,
+		},
+		{
+			"no base",
+			"",
+			"* ours",
+			"* theirs",
+			"<<<<<<< ours\n* ours\n=======\n* theirs\n>>>>>>> theirs",
+			true,
+		},
+	}
+
+	for _, inputExpected := range inputsExpecteds {
+		t.Run(inputExpected.name, func(t *testing.T) {
+			actual, conflict := Lines(inputExpected.base, inputExpected.ours, inputExpected.theirs, "ours", "theirs")
+			if actual != inputExpected.expected {
+				t.Errorf("expected %q, got %q", inputExpected.expected, actual)
+			}
+
+			if conflict != inputExpected.conflict {
+				t.Errorf("expected conflict %v, got %v", inputExpected.conflict, conflict)
+			}
+		})
+	}
+}
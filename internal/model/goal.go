@@ -5,9 +5,13 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/nvbn/termonizer/internal/utils"
+	"regexp"
+	"strings"
 	"time"
 )
 
+var checklistItem = regexp.MustCompile(`^\s*[*-]\s+\[([ xX])]\s*(.*)$`)
+
 type Goal struct {
 	ID      string
 	Period  Period
@@ -16,6 +20,92 @@ type Goal struct {
 	Updated time.Time
 }
 
+// ChecklistItem is a "* [ ] item" or "- [x] item" line of a goal
+type ChecklistItem struct {
+	// Line is the number of the line in the content from 0
+	Line int
+	Text string
+	Done bool
+}
+
+// TrashedGoal is a deleted goal that could be restored
+type TrashedGoal struct {
+	Goal
+	Deleted time.Time
+}
+
+func (g *Goal) Checklist() []ChecklistItem {
+	items := make([]ChecklistItem, 0)
+	for n, line := range strings.Split(g.Content, "\n") {
+		if match := checklistItem.FindStringSubmatch(line); match != nil {
+			items = append(items, ChecklistItem{Line: n, Text: match[2], Done: match[1] != " "})
+		}
+	}
+
+	return items
+}
+
+// SetDone checks or unchecks the checklist item on the line, false when the line isn't an item
+func (g *Goal) SetDone(line int, done bool) bool {
+	lines := strings.Split(g.Content, "\n")
+	if line < 0 || line >= len(lines) {
+		return false
+	}
+
+	match := checklistItem.FindStringSubmatchIndex(lines[line])
+	if match == nil {
+		return false
+	}
+
+	box := " "
+	if done {
+		box = "x"
+	}
+
+	lines[line] = lines[line][:match[2]] + box + lines[line][match[3]:]
+	g.Content = strings.Join(lines, "\n")
+	return true
+}
+
+// MergeChecklist checks or unchecks items with the same text and appends new ones, other lines are kept as is
+func (g *Goal) MergeChecklist(items []ChecklistItem) {
+	existing := g.Checklist()
+	matched := make([]bool, len(existing))
+
+	for _, item := range items {
+		found := false
+		for n, current := range existing {
+			if !matched[n] && current.Text == item.Text {
+				matched[n], found = true, true
+				if current.Done != item.Done {
+					g.SetDone(current.Line, item.Done)
+				}
+				break
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		box := "[ ]"
+		if item.Done {
+			box = "[x]"
+		}
+
+		line := fmt.Sprintf("* %s %s", box, item.Text)
+		if content := strings.TrimRight(g.Content, "\n"); content != "" {
+			line = content + "\n" + line
+		}
+		g.Content = line
+	}
+}
+
+// HasConflict checks for conflict markers left by sync of goals stored in files
+func (g *Goal) HasConflict() bool {
+	return strings.Contains(g.Content, "<<<<<<< ") && strings.Contains(g.Content, ">>>>>>> ")
+}
+
 func NewGoalForDay(dt time.Time) Goal {
 	return Goal{
 		ID:      uuid.New().String(),
@@ -60,6 +150,22 @@ func NewGoalForYear(dt time.Time) Goal {
 	}
 }
 
+// NewGoal creates an empty goal of the period containing the date
+func NewGoal(period Period, dt time.Time) Goal {
+	switch period {
+	case Year:
+		return NewGoalForYear(dt)
+	case Quarter:
+		return NewGoalForQuarter(dt)
+	case Week:
+		return NewGoalForWeek(dt)
+	case Day:
+		return NewGoalForDay(dt)
+	default:
+		panic("unreachable!")
+	}
+}
+
 func (g *Goal) FormatStart() string {
 	switch g.Period {
 	case Year:
@@ -70,8 +176,7 @@ func (g *Goal) FormatStart() string {
 		return fmt.Sprintf("%s Q%d", year, quarter)
 	case Week:
 		date := g.Start.Format("2006-01-02")
-		_, weekNumber := g.Start.ISOWeek()
-		return fmt.Sprintf("%s W%d", date, weekNumber)
+		return fmt.Sprintf("%s W%d", date, utils.WeekNumber(g.Start))
 	case Day:
 		date := g.Start.Format("2006-01-02")
 		weekDay := g.Start.Weekday()
@@ -81,6 +186,24 @@ func (g *Goal) FormatStart() string {
 	}
 }
 
+// End returns the first day after the period of the goal
+func (g *Goal) End() time.Time {
+	start := time.Date(g.Start.Year(), g.Start.Month(), g.Start.Day(), 0, 0, 0, 0, g.Start.Location())
+
+	switch g.Period {
+	case Year:
+		return start.AddDate(1, 0, 0)
+	case Quarter:
+		return start.AddDate(0, 3, 0)
+	case Week:
+		return utils.WeekStart(start).AddDate(0, 0, 7)
+	case Day:
+		return start.AddDate(0, 0, 1)
+	default:
+		panic("unreachable!")
+	}
+}
+
 // CompareStart look at https://pkg.go.dev/cmp, fuck it's ugly
 func (g *Goal) CompareStart(dt time.Time) int {
 	switch g.Period {
@@ -94,14 +217,7 @@ func (g *Goal) CompareStart(dt time.Time) int {
 			return compared
 		}
 	case Week:
-		goalYear, goalWeek := g.Start.ISOWeek()
-		dtYear, dtWeek := dt.ISOWeek()
-		compared := cmp.Compare(goalYear, dtYear)
-		if compared == 0 {
-			return cmp.Compare(goalWeek, dtWeek)
-		} else {
-			return compared
-		}
+		return utils.CompareDates(utils.WeekStart(g.Start), utils.WeekStart(dt))
 	case Day:
 		goalTruncated := g.Start.Truncate(24 * time.Hour)
 		dtTruncated := dt.Truncate(24 * time.Hour)
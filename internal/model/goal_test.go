@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/nvbn/termonizer/internal/utils"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -207,3 +208,89 @@ func TestGoal_CompareStart_Day(t *testing.T) {
 		t.Errorf("Expected 0 for Day comparison, got different value")
 	}
 }
+
+func TestGoal_HasConflict(t *testing.T) {
+	conflicted := Goal{Content: "<<<<<<< HEAD\n* laptop\n=======\n* desktop\n>>>>>>> origin/main\n"}
+	if !conflicted.HasConflict() {
+		t.Error("expected conflict")
+	}
+
+	resolved := Goal{Content: "* laptop\n* desktop\n"}
+	if resolved.HasConflict() {
+		t.Error("expected no conflict")
+	}
+}
+
+func TestGoal_End(t *testing.T) {
+	start := time.Date(2024, 12, 10, 15, 30, 0, 0, time.Local)
+
+	periodToExpected := map[Period]time.Time{
+		Year:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local),
+		Quarter: time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local),
+		Week:    time.Date(2024, 12, 16, 0, 0, 0, 0, time.Local),
+		Day:     time.Date(2024, 12, 11, 0, 0, 0, 0, time.Local),
+	}
+
+	for period, expected := range periodToExpected {
+		t.Run(PeriodName(period), func(t *testing.T) {
+			goal := NewGoal(period, start)
+			if end := goal.End(); !end.Equal(expected) {
+				t.Errorf("expected %v, got %v", expected, end)
+			}
+		})
+	}
+}
+
+func TestGoal_Checklist(t *testing.T) {
+	goal := Goal{Content: "# plan\n* [ ] write report\n  - [x] call Bob\n* not an item\n- [X] done"}
+
+	expected := []ChecklistItem{
+		{Line: 1, Text: "write report", Done: false},
+		{Line: 2, Text: "call Bob", Done: true},
+		{Line: 4, Text: "done", Done: true},
+	}
+
+	if items := goal.Checklist(); !reflect.DeepEqual(expected, items) {
+		t.Errorf("expected %v, got %v", expected, items)
+	}
+}
+
+func TestGoal_SetDone(t *testing.T) {
+	inputsExpecteds := []struct {
+		line     int
+		done     bool
+		ok       bool
+		expected string
+	}{
+		{1, true, true, "# plan\n* [x] write report\n  - [X] call Bob"},
+		{2, false, true, "# plan\n* [ ] write report\n  - [ ] call Bob"},
+		{2, true, true, "# plan\n* [ ] write report\n  - [x] call Bob"},
+		{0, true, false, "# plan\n* [ ] write report\n  - [X] call Bob"},
+		{5, true, false, "# plan\n* [ ] write report\n  - [X] call Bob"},
+	}
+
+	for _, tt := range inputsExpecteds {
+		goal := Goal{Content: "# plan\n* [ ] write report\n  - [X] call Bob"}
+		if ok := goal.SetDone(tt.line, tt.done); ok != tt.ok {
+			t.Errorf("%d: expected %v, got %v", tt.line, tt.ok, ok)
+		}
+
+		if goal.Content != tt.expected {
+			t.Errorf("%d: expected %q, got %q", tt.line, tt.expected, goal.Content)
+		}
+	}
+}
+
+func TestGoal_MergeChecklist(t *testing.T) {
+	goal := Goal{Content: "Notes\n# Work\n* [ ] a\n* [x] b\n* [ ] a\n"}
+	goal.MergeChecklist([]ChecklistItem{
+		{Text: "a", Done: true},
+		{Text: "b", Done: true},
+		{Text: "c", Done: false},
+	})
+
+	expected := "Notes\n# Work\n* [x] a\n* [x] b\n* [ ] a\n* [ ] c"
+	if goal.Content != expected {
+		t.Errorf("expected %q, got %q", expected, goal.Content)
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+// Layout is how period panels are arranged
+type Layout string
+
+const (
+	// LayoutAuto shows columns in wide terminals and tabs in narrow ones
+	LayoutAuto    Layout = "auto"
+	LayoutColumns Layout = "columns"
+	LayoutTabs    Layout = "tabs"
+	LayoutRows    Layout = "rows"
+)
+
+var Layouts = []Layout{LayoutAuto, LayoutColumns, LayoutTabs, LayoutRows}
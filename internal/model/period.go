@@ -1,5 +1,10 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Period = int
 
 const (
@@ -26,3 +31,14 @@ func PeriodName(p Period) string {
 	}
 
 }
+
+// ParsePeriod is the reverse of PeriodName, case doesn't matter
+func ParsePeriod(name string) (Period, error) {
+	for _, period := range Periods {
+		if strings.EqualFold(PeriodName(period), name) {
+			return period, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown period %s", name)
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurring is an item added to every new goal matching its schedule:
+//
+//	daily - every day goal
+//	weekdays - day goals from monday to friday
+//	monday, tuesday, ... - day goals on the weekday
+//	weekly - every week goal
+//	monthly:N - day goals on the N-th day of the month
+//	quarterly - every quarter goal
+type Recurring struct {
+	ID       string
+	Schedule string
+	Content  string
+	Updated  time.Time
+}
+
+func NewRecurring(schedule string, content string, dt time.Time) (Recurring, error) {
+	if err := ValidateSchedule(schedule); err != nil {
+		return Recurring{}, err
+	}
+
+	return Recurring{
+		ID:       uuid.New().String(),
+		Schedule: schedule,
+		Content:  content,
+		Updated:  dt,
+	}, nil
+}
+
+func ValidateSchedule(schedule string) error {
+	switch schedule {
+	case "daily", "weekdays", "weekly", "quarterly":
+		return nil
+	}
+
+	if _, ok := parseWeekday(schedule); ok {
+		return nil
+	}
+
+	if day, ok := strings.CutPrefix(schedule, "monthly:"); ok {
+		n, err := strconv.Atoi(day)
+		if err != nil || n < 1 || n > 31 {
+			return fmt.Errorf("invalid day of month %s", day)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown schedule %s", schedule)
+}
+
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+
+	return 0, false
+}
+
+// Matches checks if the item should be added to the goal
+func (r *Recurring) Matches(goal Goal) bool {
+	switch r.Schedule {
+	case "daily":
+		return goal.Period == Day
+	case "weekdays":
+		return goal.Period == Day && goal.Start.Weekday() != time.Saturday && goal.Start.Weekday() != time.Sunday
+	case "weekly":
+		return goal.Period == Week
+	case "quarterly":
+		return goal.Period == Quarter
+	}
+
+	if weekday, ok := parseWeekday(r.Schedule); ok {
+		return goal.Period == Day && goal.Start.Weekday() == weekday
+	}
+
+	if day, ok := strings.CutPrefix(r.Schedule, "monthly:"); ok {
+		n, err := strconv.Atoi(day)
+		return err == nil && goal.Period == Day && goal.Start.Day() == n
+	}
+
+	return false
+}
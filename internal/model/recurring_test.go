@@ -0,0 +1,56 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRecurring_Matches(t *testing.T) {
+	tuesday := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+	saturday := time.Date(2024, 12, 14, 0, 0, 0, 0, time.Local)
+
+	type testData struct {
+		schedule string
+		goal     Goal
+		expected bool
+	}
+
+	inputsExpecteds := []testData{
+		{"daily", Goal{Period: Day, Start: saturday}, true},
+		{"daily", Goal{Period: Week, Start: tuesday}, false},
+		{"weekdays", Goal{Period: Day, Start: tuesday}, true},
+		{"weekdays", Goal{Period: Day, Start: saturday}, false},
+		{"tuesday", Goal{Period: Day, Start: tuesday}, true},
+		{"Monday", Goal{Period: Day, Start: tuesday}, false},
+		{"weekly", Goal{Period: Week, Start: tuesday}, true},
+		{"weekly", Goal{Period: Day, Start: tuesday}, false},
+		{"monthly:10", Goal{Period: Day, Start: tuesday}, true},
+		{"monthly:11", Goal{Period: Day, Start: tuesday}, false},
+		{"quarterly", Goal{Period: Quarter, Start: tuesday}, true},
+		{"quarterly", Goal{Period: Year, Start: tuesday}, false},
+	}
+
+	for _, inputExpected := range inputsExpecteds {
+		t.Run(fmt.Sprintf("%s %s", inputExpected.schedule, inputExpected.goal.FormatStart()), func(t *testing.T) {
+			r := Recurring{Schedule: inputExpected.schedule}
+			if actual := r.Matches(inputExpected.goal); actual != inputExpected.expected {
+				t.Errorf("got %v, want %v", actual, inputExpected.expected)
+			}
+		})
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	for _, schedule := range []string{"daily", "weekdays", "friday", "weekly", "monthly:31", "quarterly"} {
+		if err := ValidateSchedule(schedule); err != nil {
+			t.Errorf("unexpected error for %s: %v", schedule, err)
+		}
+	}
+
+	for _, schedule := range []string{"", "yearly", "monthly:0", "monthly:32", "monthly:first"} {
+		if err := ValidateSchedule(schedule); err == nil {
+			t.Errorf("expected error for %s", schedule)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// a time at the start of an item like "* 14:00 standup" or "* [ ] 14:00 standup", or "@15:30" anywhere in a line
+var reminderTime = regexp.MustCompile(`(?:^\s*[*-]\s+(?:\[[ xX]]\s+)?|@)(\d{1,2}):(\d{2})\b`)
+
+var listMarker = regexp.MustCompile(`^\s*[*-]\s+(?:\[[ xX]]\s+)?`)
+
+// Reminder is a line of a day goal with a time annotation
+type Reminder struct {
+	// Line is the number of the line in the content from 0
+	Line int
+	At   time.Time
+	Text string
+}
+
+// Reminders finds time annotations of a day goal, checked items don't remind
+func (g *Goal) Reminders() []Reminder {
+	reminders := make([]Reminder, 0)
+	if g.Period != Day {
+		return reminders
+	}
+
+	for n, line := range strings.Split(g.Content, "\n") {
+		match := reminderTime.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		if item := checklistItem.FindStringSubmatch(line); item != nil && item[1] != " " {
+			continue
+		}
+
+		hour, _ := strconv.Atoi(match[1])
+		minute, _ := strconv.Atoi(match[2])
+		if hour > 23 || minute > 59 {
+			continue
+		}
+
+		reminders = append(reminders, Reminder{
+			Line: n,
+			At:   time.Date(g.Start.Year(), g.Start.Month(), g.Start.Day(), hour, minute, 0, 0, time.Local),
+			Text: strings.TrimSpace(listMarker.ReplaceAllString(line, "")),
+		})
+	}
+
+	return reminders
+}
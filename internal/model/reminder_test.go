@@ -0,0 +1,33 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGoal_Reminders(t *testing.T) {
+	day := NewGoalForDay(time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local))
+	day.Content = "# Tuesday\n* 9:30 standup\n* [ ] 14:00 review\n* [x] 15:00 done already\n" +
+		"call Bob @16:45\n* 25:00 not a time\n* at 10:00 isn't at the start\nv1.12:30"
+
+	at := func(hour int, minute int) time.Time {
+		return time.Date(2024, 12, 10, hour, minute, 0, 0, time.Local)
+	}
+
+	expected := []Reminder{
+		{Line: 1, At: at(9, 30), Text: "9:30 standup"},
+		{Line: 2, At: at(14, 0), Text: "14:00 review"},
+		{Line: 4, At: at(16, 45), Text: "call Bob @16:45"},
+	}
+
+	if reminders := day.Reminders(); !reflect.DeepEqual(expected, reminders) {
+		t.Errorf("expected %v, got %v", expected, reminders)
+	}
+
+	week := NewGoalForWeek(time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local))
+	week.Content = "* 9:30 standup"
+	if reminders := week.Reminders(); len(reminders) != 0 {
+		t.Errorf("expected no reminders, got %v", reminders)
+	}
+}
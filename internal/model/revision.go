@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Revision is a saved version of goal content, used as a merge base
+type Revision struct {
+	GoalID  string
+	Content string
+	Updated time.Time
+}
@@ -0,0 +1,189 @@
+package org
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nvbn/termonizer/internal/model"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// content is indented in headings, so markdown lists aren't parsed as org headings
+const indent = "  "
+
+var (
+	heading    = regexp.MustCompile(`^(\*+)\s+(?:(TODO|DONE)\s+)?(.*?)\s*$`)
+	scheduled  = regexp.MustCompile(`^\s*SCHEDULED:\s*<(\d{4}-\d{2}-\d{2})[^>]*>`)
+	property   = regexp.MustCompile(`^\s*:([A-Za-z_]+):\s*(.*?)\s*$`)
+	checkboxes = regexp.MustCompile(`^(\s*[*-]\s+)\[x]`)
+	orgChecked = regexp.MustCompile(`^(\s*[*-]\s+)\[X]`)
+)
+
+// Write exports goals as top level headings scheduled on the start of their periods, empty goals are skipped
+func Write(out io.Writer, goals []model.Goal) error {
+	w := bufio.NewWriter(out)
+
+	for _, goal := range goals {
+		if strings.TrimSpace(goal.Content) == "" {
+			continue
+		}
+
+		fmt.Fprintf(w, "* %s %s\n", model.PeriodName(goal.Period), goal.FormatStart())
+		fmt.Fprintf(w, "%sSCHEDULED: <%s>\n", indent, goal.Start.Format("2006-01-02 Mon"))
+		fmt.Fprintf(w, "%s:PROPERTIES:\n", indent)
+		fmt.Fprintf(w, "%s:ID: %s\n", indent, goal.ID)
+		fmt.Fprintf(w, "%s:PERIOD: %s\n", indent, strings.ToLower(model.PeriodName(goal.Period)))
+		fmt.Fprintf(w, "%s:END:\n", indent)
+
+		for _, line := range strings.Split(strings.TrimRight(goal.Content, "\n"), "\n") {
+			if line == "" {
+				fmt.Fprintln(w)
+				continue
+			}
+
+			// org only understands upper case checked boxes
+			fmt.Fprintln(w, indent+checkboxes.ReplaceAllString(line, "${1}[X]"))
+		}
+	}
+
+	return w.Flush()
+}
+
+type parsedHeading struct {
+	title      string
+	start      string
+	properties map[string]string
+	lines      []string
+}
+
+// toGoal uses :PERIOD: and :ID: written by Write, the period is guessed from the title for other files
+func (h *parsedHeading) toGoal() (model.Goal, error) {
+	if h.start == "" {
+		return model.Goal{}, fmt.Errorf("heading %q isn't scheduled", h.title)
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", h.start, time.Local)
+	if err != nil {
+		return model.Goal{}, fmt.Errorf("heading %q: %w", h.title, err)
+	}
+
+	periodName, ok := h.properties["PERIOD"]
+	if !ok {
+		periodName, _, _ = strings.Cut(h.title, " ")
+	}
+
+	period, err := model.ParsePeriod(periodName)
+	if err != nil {
+		period = model.Day
+	}
+
+	goal := model.NewGoal(period, start)
+	if id, ok := h.properties["ID"]; ok {
+		goal.ID = id
+	} else {
+		goal.ID = uuid.New().String()
+	}
+
+	// blank lines between headings belong to the file, not to the goal
+	for len(h.lines) > 0 && strings.TrimSpace(h.lines[len(h.lines)-1]) == "" {
+		h.lines = h.lines[:len(h.lines)-1]
+	}
+	goal.Content = strings.Join(h.lines, "\n")
+
+	return goal, nil
+}
+
+// subheading converts "** TODO item" to "* [ ] item", deeper headings are nested items
+func subheading(level int, keyword string, title string) string {
+	prefix := strings.Repeat(indent, level-2) + "* "
+	switch keyword {
+	case "TODO":
+		return prefix + "[ ] " + title
+	case "DONE":
+		return prefix + "[x] " + title
+	default:
+		return prefix + title
+	}
+}
+
+// Read imports top level headings as goals, they should be scheduled on a day of the period
+func Read(in io.Reader) ([]model.Goal, error) {
+	goals := make([]model.Goal, 0)
+
+	var current *parsedHeading
+	inProperties := false
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+
+		goal, err := current.toGoal()
+		if err != nil {
+			return err
+		}
+
+		goals = append(goals, goal)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if match := heading.FindStringSubmatch(line); match != nil {
+			if len(match[1]) == 1 {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+
+				current = &parsedHeading{title: match[3], properties: make(map[string]string)}
+				continue
+			}
+
+			if current != nil {
+				current.lines = append(current.lines, subheading(len(match[1]), match[2], match[3]))
+			}
+			continue
+		}
+
+		// text before the first heading isn't a part of any goal
+		if current == nil {
+			continue
+		}
+
+		if match := scheduled.FindStringSubmatch(line); match != nil && current.start == "" && len(current.lines) == 0 {
+			current.start = match[1]
+			continue
+		}
+
+		if strings.TrimSpace(line) == ":PROPERTIES:" && len(current.lines) == 0 {
+			inProperties = true
+			continue
+		}
+
+		if inProperties {
+			if strings.TrimSpace(line) == ":END:" {
+				inProperties = false
+			} else if match := property.FindStringSubmatch(line); match != nil {
+				current.properties[strings.ToUpper(match[1])] = match[2]
+			}
+			continue
+		}
+
+		// checked boxes are written in upper case for org, the app checks them in lower case
+		current.lines = append(current.lines, orgChecked.ReplaceAllString(strings.TrimPrefix(line, indent), "${1}[x]"))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
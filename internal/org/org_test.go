@@ -0,0 +1,95 @@
+package org
+
+import (
+	"bytes"
+	"github.com/nvbn/termonizer/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)
+	goals := []model.Goal{
+		{ID: "week", Period: model.Week, Start: start, Content: "# Work\n* [ ] write report\n* [x] call Bob\n\n* not an item"},
+		{ID: "day", Period: model.Day, Start: start, Content: "just text\n**bold** start"},
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, goals); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	read, err := Read(&out)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for n := range read {
+		read[n].Updated = time.Time{}
+	}
+
+	if !reflect.DeepEqual(goals, read) {
+		t.Errorf("expected %v, got %v", goals, read)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)
+	goals := []model.Goal{
+		{ID: "week", Period: model.Week, Start: start, Content: "* [x] call Bob"},
+		{ID: "empty", Period: model.Day, Start: start, Content: ""},
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, goals); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := `* Week 2024-12-09 W50
+  SCHEDULED: <2024-12-09 Mon>
+  :PROPERTIES:
+  :ID: week
+  :PERIOD: week
+  :END:
+  * [X] call Bob
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestRead_Native(t *testing.T) {
+	input := `#+TITLE: plan
+* Quarter goals
+SCHEDULED: <2024-11-02 Sat>
+** TODO ship the release
+** DONE hire
+*** TODO onboarding
+notes
+* Unscheduled
+`
+
+	if _, err := Read(strings.NewReader(input)); err == nil {
+		t.Error("expected error for the heading without a date")
+	}
+
+	goals, err := Read(strings.NewReader(strings.TrimSuffix(input, "* Unscheduled\n")))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(goals) != 1 {
+		t.Fatalf("expected 1 goal, got %v", goals)
+	}
+
+	if goals[0].Period != model.Quarter || goals[0].FormatStart() != "2024 Q4" {
+		t.Errorf("expected 2024 Q4 goal, got %v", goals[0])
+	}
+
+	expected := "* [ ] ship the release\n* [x] hire\n  * [ ] onboarding\nnotes"
+	if goals[0].Content != expected {
+		t.Errorf("expected %q, got %q", expected, goals[0].Content)
+	}
+}
@@ -0,0 +1,25 @@
+package reminders
+
+import (
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"os/exec"
+	"strings"
+)
+
+// Command notifies by running the command with the text of the reminder as the last argument, e.g. notify-send,
+// an empty command doesn't notify
+func Command(args []string) func(reminder model.Reminder) error {
+	return func(reminder model.Reminder) error {
+		if len(args) == 0 {
+			return nil
+		}
+
+		output, err := exec.Command(args[0], append(args[1:], reminder.Text)...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to notify with %s: %w: %s", args[0], err, strings.TrimSpace(string(output)))
+		}
+
+		return nil
+	}
+}
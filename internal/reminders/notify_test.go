@@ -0,0 +1,38 @@
+package reminders
+
+import (
+	"github.com/nvbn/termonizer/internal/model"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notified")
+	notify := Command([]string{"sh", "-c", `printf %s "$1" > "$0"`, path})
+
+	if err := notify(model.Reminder{Text: "14:00 standup"}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if string(content) != "14:00 standup" {
+		t.Errorf("expected the text of the reminder, got %q", content)
+	}
+}
+
+func TestCommand_Failed(t *testing.T) {
+	if err := Command([]string{"false"})(model.Reminder{Text: "standup"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestCommand_Empty(t *testing.T) {
+	if err := Command(nil)(model.Reminder{Text: "standup"}); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
@@ -0,0 +1,76 @@
+package reminders
+
+import (
+	"context"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"log"
+	"time"
+)
+
+// CheckInterval is how often goals are read, so reminders come at most that late
+const CheckInterval = 15 * time.Second
+
+type goalsRepository interface {
+	FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error)
+}
+
+// Scheduler fires reminders of day goals once their time comes, reminders missed while it wasn't running are skipped
+type Scheduler struct {
+	timeNow func() time.Time
+	goals   goalsRepository
+	notify  func(reminder model.Reminder)
+	checked time.Time // reminders up to the time are already fired
+}
+
+func NewScheduler(timeNow func() time.Time, goals goalsRepository, notify func(reminder model.Reminder)) *Scheduler {
+	return &Scheduler{
+		timeNow: timeNow,
+		goals:   goals,
+		notify:  notify,
+		checked: timeNow(),
+	}
+}
+
+// Check fires reminders that became due since the previous check
+func (s *Scheduler) Check(ctx context.Context) error {
+	now := s.timeNow()
+
+	goals, err := s.goals.FindForPeriod(ctx, model.Day)
+	if err != nil {
+		return fmt.Errorf("unable to read goals: %w", err)
+	}
+
+	for _, goal := range goals {
+		// the previous check could be before midnight
+		if goal.CompareStart(now) != 0 && goal.CompareStart(s.checked) != 0 {
+			continue
+		}
+
+		for _, reminder := range goal.Reminders() {
+			if reminder.At.After(s.checked) && !reminder.At.After(now) {
+				s.notify(reminder)
+			}
+		}
+	}
+
+	s.checked = now
+	return nil
+}
+
+// Run checks reminders every interval until the context is done
+func (s *Scheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Check(ctx); err != nil {
+				log.Printf("failed to check reminders: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package reminders
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type goalsRepositoryMock struct {
+	goals []model.Goal
+}
+
+func (r *goalsRepositoryMock) FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error) {
+	return r.goals, nil
+}
+
+func TestScheduler_Check(t *testing.T) {
+	now := time.Date(2026, 10, 19, 13, 59, 50, 0, time.Local)
+	goals := &goalsRepositoryMock{goals: []model.Goal{
+		{ID: "today", Period: model.Day, Start: now, Content: "* 14:00 standup\n* 13:00 lunch\nreview @14:00\n* [x] 14:00 done"},
+		{ID: "tomorrow", Period: model.Day, Start: now.AddDate(0, 0, 1), Content: "* 14:00 tomorrow"},
+	}}
+
+	fired := make([]string, 0)
+	s := NewScheduler(func() time.Time { return now }, goals, func(reminder model.Reminder) {
+		fired = append(fired, reminder.Text)
+	})
+
+	inputsExpecteds := []struct {
+		now      time.Time
+		expected []string
+	}{
+		{now.Add(5 * time.Second), []string{}},
+		{now.Add(15 * time.Second), []string{"14:00 standup", "review @14:00"}},
+		{now.Add(30 * time.Second), []string{"14:00 standup", "review @14:00"}},
+	}
+
+	for _, inputExpected := range inputsExpecteds {
+		now = inputExpected.now
+		if err := s.Check(t.Context()); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+
+		if !reflect.DeepEqual(fired, inputExpected.expected) {
+			t.Errorf("at %v expected %v, got %v", now, inputExpected.expected, fired)
+		}
+	}
+}
+
+func TestScheduler_Check_Midnight(t *testing.T) {
+	now := time.Date(2026, 10, 19, 23, 59, 50, 0, time.Local)
+	goals := &goalsRepositoryMock{goals: []model.Goal{
+		{ID: "today", Period: model.Day, Start: now, Content: "* 23:59 late"},
+		{ID: "tomorrow", Period: model.Day, Start: now.AddDate(0, 0, 1), Content: "* 0:00 early"},
+	}}
+
+	fired := make([]string, 0)
+	s := NewScheduler(func() time.Time { return now }, goals, func(reminder model.Reminder) {
+		fired = append(fired, reminder.Text)
+	})
+
+	now = now.Add(15 * time.Second)
+	if err := s.Check(t.Context()); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !reflect.DeepEqual(fired, []string{"0:00 early"}) {
+		t.Errorf("expected the reminder after midnight, got %v", fired)
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/utils"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const snapshotLayout = "termonizer-2006-01-02T15-04-05.db"
+
+type backupStorage interface {
+	Backup(ctx context.Context, path string) error
+	Restore(ctx context.Context, path string) error
+}
+
+// Retention is how many of the latest daily, weekly and monthly snapshots are kept,
+// a snapshot is kept when it's the latest in any of them
+type Retention struct {
+	Daily   int
+	Weekly  int
+	Monthly int
+}
+
+type Snapshot struct {
+	Name    string
+	Path    string
+	Created time.Time
+}
+
+type Backups struct {
+	timeNow   func() time.Time
+	dir       string
+	retention Retention
+	storage   backupStorage
+}
+
+func NewBackups(timeNow func() time.Time, dir string, retention Retention, storage backupStorage) *Backups {
+	return &Backups{
+		timeNow:   timeNow,
+		dir:       dir,
+		retention: retention,
+		storage:   storage,
+	}
+}
+
+// List returns snapshots from the newest, unrelated files in the directory are ignored
+func (b *Backups) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(b.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return make([]Snapshot, 0), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read backups: %w", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(entries))
+	for _, entry := range entries {
+		created, err := time.ParseInLocation(snapshotLayout, entry.Name(), time.Local)
+		if err != nil || entry.IsDir() {
+			continue
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Name:    entry.Name(),
+			Path:    filepath.Join(b.dir, entry.Name()),
+			Created: created,
+		})
+	}
+
+	slices.SortFunc(snapshots, func(a, b Snapshot) int { return b.Created.Compare(a.Created) })
+
+	return snapshots, nil
+}
+
+// Create snapshots the database and removes snapshots outside the retention
+func (b *Backups) Create(ctx context.Context) (Snapshot, error) {
+	snapshot, err := b.snapshot(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return snapshot, b.Rotate()
+}
+
+func (b *Backups) snapshot(ctx context.Context) (Snapshot, error) {
+	if err := os.MkdirAll(b.dir, 0700); err != nil {
+		return Snapshot{}, fmt.Errorf("unable to create backups dir: %w", err)
+	}
+
+	created := b.timeNow()
+	name := created.Format(snapshotLayout)
+	snapshot := Snapshot{Name: name, Path: filepath.Join(b.dir, name), Created: created}
+
+	if err := b.storage.Backup(ctx, snapshot.Path); err != nil {
+		os.Remove(snapshot.Path)
+		return Snapshot{}, fmt.Errorf("unable to backup: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// Daily creates a snapshot when there's none for today, returns false when it already exists
+func (b *Backups) Daily(ctx context.Context) (bool, error) {
+	snapshots, err := b.List()
+	if err != nil {
+		return false, err
+	}
+
+	if len(snapshots) > 0 && utils.CompareDates(snapshots[0].Created, b.timeNow()) == 0 {
+		return false, nil
+	}
+
+	if _, err := b.Create(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Rotate removes snapshots outside the retention
+func (b *Backups) Rotate() error {
+	snapshots, err := b.List()
+	if err != nil {
+		return err
+	}
+
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	months := make(map[string]bool)
+	keepLatest := func(buckets map[string]bool, bucket string, limit int) bool {
+		if buckets[bucket] || len(buckets) >= limit {
+			return false
+		}
+		buckets[bucket] = true
+		return true
+	}
+
+	for _, snapshot := range snapshots {
+		keepDay := keepLatest(days, snapshot.Created.Format("2006-01-02"), b.retention.Daily)
+		keepWeek := keepLatest(weeks, utils.WeekStart(snapshot.Created).Format("2006-01-02"), b.retention.Weekly)
+		keepMonth := keepLatest(months, snapshot.Created.Format("2006-01"), b.retention.Monthly)
+		if keepDay || keepWeek || keepMonth {
+			continue
+		}
+
+		if err := os.Remove(snapshot.Path); err != nil {
+			return fmt.Errorf("unable to remove old snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Restore replaces the database with the snapshot found by name or its unique prefix,
+// the current state is snapshotted first so the restore could be reverted, without rotation
+// as it could remove the snapshot being restored
+func (b *Backups) Restore(ctx context.Context, name string) (Snapshot, error) {
+	snapshots, err := b.List()
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	snapshot, err := findByPrefix(snapshots, func(s Snapshot) string { return s.Name }, "snapshot", strings.TrimSuffix(filepath.Base(name), ".db"))
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	if _, err := b.snapshot(ctx); err != nil {
+		return Snapshot{}, err
+	}
+
+	if err := b.storage.Restore(ctx, snapshot.Path); err != nil {
+		return Snapshot{}, fmt.Errorf("unable to restore: %w", err)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type backupStorageMock struct {
+	restored string
+}
+
+func (m *backupStorageMock) Backup(ctx context.Context, path string) error {
+	return os.WriteFile(path, []byte("snapshot"), 0600)
+}
+
+func (m *backupStorageMock) Restore(ctx context.Context, path string) error {
+	m.restored = path
+	return nil
+}
+
+func TestBackups_Rotate(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	now := time.Date(2024, 12, 10, 12, 0, 0, 0, time.Local)
+	b := NewBackups(func() time.Time { return now }, dir, Retention{Daily: 2, Weekly: 2, Monthly: 2}, &backupStorageMock{})
+
+	// a snapshot per day for two months
+	for day := 0; day < 60; day++ {
+		now = time.Date(2024, 10, 12, 12, 0, 0, 0, time.Local).AddDate(0, 0, day)
+		if created, err := b.Daily(ctx); err != nil || !created {
+			t.Fatalf("expected snapshot to be created, got %v %v", created, err)
+		}
+	}
+
+	if created, err := b.Daily(ctx); err != nil || created {
+		t.Errorf("expected only one snapshot per day, got %v %v", created, err)
+	}
+
+	snapshots, err := b.List()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := []string{
+		"termonizer-2024-12-10T12-00-00.db", // daily, weekly and monthly
+		"termonizer-2024-12-09T12-00-00.db", // daily
+		"termonizer-2024-12-08T12-00-00.db", // weekly
+		"termonizer-2024-11-30T12-00-00.db", // monthly
+	}
+
+	if len(snapshots) != len(expected) {
+		t.Fatalf("expected %d snapshots, got %v", len(expected), snapshots)
+	}
+
+	for n, snapshot := range snapshots {
+		if snapshot.Name != expected[n] {
+			t.Errorf("expected %s, got %s", expected[n], snapshot.Name)
+		}
+	}
+}
+
+func TestBackups_Restore(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	now := time.Date(2024, 12, 9, 12, 0, 0, 0, time.Local)
+	storage := &backupStorageMock{}
+	b := NewBackups(func() time.Time { return now }, dir, Retention{Daily: 1}, storage)
+
+	if _, err := b.Create(ctx); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := b.Restore(ctx, "termonizer-2023"); err == nil {
+		t.Error("expected error for unknown snapshot")
+	}
+
+	now = now.Add(time.Hour)
+	restored, err := b.Restore(ctx, "termonizer-2024-12-09T12")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if storage.restored != filepath.Join(dir, "termonizer-2024-12-09T12-00-00.db") || restored.Path != storage.restored {
+		t.Errorf("unexpected restored snapshot %s", storage.restored)
+	}
+
+	snapshots, err := b.List()
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(snapshots) != 2 {
+		t.Errorf("expected the current state to be snapshotted before restore, got %v", snapshots)
+	}
+}
@@ -2,106 +2,266 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/storage"
 	"slices"
+	"strings"
 	"time"
 )
 
+var ErrGoalNotFound = errors.New("goal not found")
+
 type goalsStorage interface {
 	ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error)
 	CountGoalsForPeriod(ctx context.Context, period int) (int, error)
 	UpdateGoal(ctx context.Context, goals model.Goal) error
+	Tx(ctx context.Context, fn func(tx storage.GoalsTx) error) error
+	TrashGoal(ctx context.Context, id string, deleted time.Time) error
+	SearchGoals(ctx context.Context, query string) ([]model.Goal, error)
+	ReadRevisions(ctx context.Context, goalID string) ([]model.Revision, error)
+}
+
+type recurringReader interface {
+	ReadRecurring(ctx context.Context) ([]model.Recurring, error)
 }
 
 type Goals struct {
-	timeNow func() time.Time
-	storage goalsStorage
+	timeNow   func() time.Time
+	storage   goalsStorage
+	templates *Templates
+	recurring recurringReader
 }
 
-func NewGoalsRepository(timeNow func() time.Time, storage goalsStorage) *Goals {
+func NewGoalsRepository(timeNow func() time.Time, storage goalsStorage, templates *Templates, recurring recurringReader) *Goals {
 	return &Goals{
-		timeNow: timeNow,
-		storage: storage,
+		timeNow:   timeNow,
+		storage:   storage,
+		templates: templates,
+		recurring: recurring,
+	}
+}
+
+// prefill sets content of a goal created by padding from the template for its period and recurring items
+func (r *Goals) prefill(goal model.Goal, recurring []model.Recurring) model.Goal {
+	items := make([]string, 0)
+	for _, item := range recurring {
+		if item.Matches(goal) {
+			items = append(items, item.Content)
+		}
+	}
+
+	goal.Content = r.templates.Render(goal)
+	if len(items) > 0 {
+		if goal.Content != "" {
+			goal.Content = strings.TrimRight(goal.Content, "\n") + "\n"
+		}
+		goal.Content += strings.Join(items, "\n")
 	}
+
+	return goal
 }
 
-func (r *Goals) padYears(goals []model.Goal) []model.Goal {
+func (r *Goals) padYears(goals []model.Goal, recurring []model.Recurring) []model.Goal {
 	now := r.timeNow()
 	if len(goals) == 0 || goals[0].CompareStart(now) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForYear(now))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForYear(now), recurring))
 	}
 
 	nowNextYear := now.AddDate(1, 0, 0)
 	if goals[0].CompareStart(nowNextYear) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForYear(nowNextYear))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForYear(nowNextYear), recurring))
 	}
 
 	return goals
 }
 
-func (r *Goals) padQuarters(goals []model.Goal) []model.Goal {
+func (r *Goals) padQuarters(goals []model.Goal, recurring []model.Recurring) []model.Goal {
 	now := r.timeNow()
 	if len(goals) == 0 || goals[0].CompareStart(now) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForQuarter(now))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForQuarter(now), recurring))
 	}
 
 	nowNextQuarter := now.AddDate(0, 3, 0)
 	if goals[0].CompareStart(nowNextQuarter) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForQuarter(nowNextQuarter))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForQuarter(nowNextQuarter), recurring))
 	}
 
 	return goals
 }
 
-func (r *Goals) padWeeks(goals []model.Goal) []model.Goal {
+func (r *Goals) padWeeks(goals []model.Goal, recurring []model.Recurring) []model.Goal {
 	now := r.timeNow()
 	if len(goals) == 0 || goals[0].CompareStart(now) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForWeek(now))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForWeek(now), recurring))
 	}
 
 	nowNextWeek := now.AddDate(0, 0, 7)
 	if goals[0].CompareStart(nowNextWeek) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForWeek(nowNextWeek))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForWeek(nowNextWeek), recurring))
 	}
 
 	return goals
 }
 
-func (r *Goals) padDays(goals []model.Goal) []model.Goal {
+func (r *Goals) padDays(goals []model.Goal, recurring []model.Recurring) []model.Goal {
 	now := r.timeNow()
 	if len(goals) == 0 || goals[0].CompareStart(now) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForDay(now))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForDay(now), recurring))
 	}
 
 	nowNextDay := now.AddDate(0, 0, 1)
 	if goals[0].CompareStart(nowNextDay) == -1 {
-		goals = slices.Insert(goals, 0, model.NewGoalForDay(nowNextDay))
+		goals = slices.Insert(goals, 0, r.prefill(model.NewGoalForDay(nowNextDay), recurring))
 	}
 
 	return goals
 }
 
 func (r *Goals) FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error) {
-	goals, err := r.storage.ReadGoalsForPeriod(ctx, period)
+	return r.findForPeriod(ctx, r.storage, period)
+}
+
+func (r *Goals) findForPeriod(ctx context.Context, tx storage.GoalsTx, period model.Period) ([]model.Goal, error) {
+	goals, err := tx.ReadGoalsForPeriod(ctx, period)
 	if err != nil {
 		return nil, fmt.Errorf("unable to read goals: %w", err)
 	}
 
+	recurring, err := r.recurring.ReadRecurring(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read recurring: %w", err)
+	}
+
 	switch period {
 	case model.Year:
-		return r.padYears(goals), nil
+		return r.padYears(goals, recurring), nil
 	case model.Quarter:
-		return r.padQuarters(goals), nil
+		return r.padQuarters(goals, recurring), nil
 	case model.Week:
-		return r.padWeeks(goals), nil
+		return r.padWeeks(goals, recurring), nil
 	case model.Day:
-		return r.padDays(goals), nil
+		return r.padDays(goals, recurring), nil
 	default:
 		panic("unreachable!")
 	}
 }
 
+// All returns stored goals of every period, goals padded by FindForPeriod aren't stored until they're updated
+func (r *Goals) All(ctx context.Context) ([]model.Goal, error) {
+	return allGoals(ctx, r.storage)
+}
+
+func allGoals(ctx context.Context, tx storage.GoalsTx) ([]model.Goal, error) {
+	goals := make([]model.Goal, 0)
+	for _, period := range model.Periods {
+		stored, err := tx.ReadGoalsForPeriod(ctx, period)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read goals: %w", err)
+		}
+
+		goals = append(goals, stored...)
+	}
+
+	return goals, nil
+}
+
+func (r *Goals) Find(ctx context.Context, id string) (model.Goal, error) {
+	goals, err := r.All(ctx)
+	if err != nil {
+		return model.Goal{}, err
+	}
+
+	for _, goal := range goals {
+		if goal.ID == id {
+			return goal, nil
+		}
+	}
+
+	return model.Goal{}, ErrGoalNotFound
+}
+
+// Import stores goals, a goal with the same id is replaced, so exported goals could be edited and imported back,
+// content of a goal for a period that already has another goal is appended to it unless it's already there,
+// returns amount of changed goals, nothing is imported when a goal fails
+func (r *Goals) Import(ctx context.Context, goals []model.Goal) (int, error) {
+	return r.importInTx(ctx, goals, func(stored model.Goal, goal model.Goal) model.Goal {
+		if stored.ID == goal.ID {
+			return goal
+		}
+
+		// the same file imported twice
+		if strings.Contains(stored.Content, goal.Content) {
+			return stored
+		}
+
+		stored.Content = strings.TrimRight(stored.Content, "\n") + "\n" + goal.Content
+		return stored
+	})
+}
+
+// ImportChecklists merges checklist items of goals into stored goals with the same id or for the same period,
+// as formats like todo.txt have only items, so other lines of stored goals are kept, returns amount of changed goals
+func (r *Goals) ImportChecklists(ctx context.Context, goals []model.Goal) (int, error) {
+	return r.importInTx(ctx, goals, func(stored model.Goal, goal model.Goal) model.Goal {
+		stored.MergeChecklist(goal.Checklist())
+		return stored
+	})
+}
+
+func (r *Goals) importInTx(ctx context.Context, goals []model.Goal, merge func(stored model.Goal, goal model.Goal) model.Goal) (int, error) {
+	changed := 0
+	err := r.Tx(ctx, func(tx *GoalsTx) error {
+		var err error
+		changed, err = tx.importGoals(ctx, goals, merge)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return changed, nil
+}
+
+// importGoals stores goals without a stored counterpart as is and merges others into it
+func (t *GoalsTx) importGoals(ctx context.Context, goals []model.Goal, merge func(stored model.Goal, goal model.Goal) model.Goal) (int, error) {
+	stored, err := t.All(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, goal := range goals {
+		existing := slices.IndexFunc(stored, func(s model.Goal) bool { return s.ID == goal.ID })
+		if existing == -1 {
+			existing = slices.IndexFunc(stored, func(s model.Goal) bool {
+				return s.Period == goal.Period && s.CompareStart(goal.Start) == 0
+			})
+		}
+
+		if existing != -1 {
+			goal = merge(stored[existing], goal)
+			if stored[existing].Content == goal.Content {
+				continue
+			}
+		}
+
+		if err := t.Update(ctx, goal); err != nil {
+			return changed, fmt.Errorf("unable to import goal: %w", err)
+		}
+
+		if existing == -1 {
+			stored = append(stored, goal)
+		} else {
+			stored[existing] = goal
+		}
+		changed += 1
+	}
+
+	return changed, nil
+}
+
 func (r *Goals) CountForPeriod(ctx context.Context, period model.Period) (int, error) {
 	return r.storage.CountGoalsForPeriod(ctx, period)
 }
@@ -110,3 +270,72 @@ func (r *Goals) Update(ctx context.Context, goal model.Goal) error {
 	goal.Updated = r.timeNow()
 	return r.storage.UpdateGoal(ctx, goal)
 }
+
+// UpdateMany stores all goals at once, e.g. when an item is moved between goals, so a goal isn't left without
+// the item or with it twice
+func (r *Goals) UpdateMany(ctx context.Context, goals []model.Goal) error {
+	return r.Tx(ctx, func(tx *GoalsTx) error {
+		for _, goal := range goals {
+			if err := tx.Update(ctx, goal); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GoalsTx reads and changes goals in a unit of work started by Goals.Tx
+type GoalsTx struct {
+	goals   *Goals
+	storage storage.GoalsTx
+}
+
+// FindForPeriod is Goals.FindForPeriod that sees changes made in the unit of work
+func (t *GoalsTx) FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error) {
+	return t.goals.findForPeriod(ctx, t.storage, period)
+}
+
+// All is Goals.All that sees changes made in the unit of work
+func (t *GoalsTx) All(ctx context.Context) ([]model.Goal, error) {
+	return allGoals(ctx, t.storage)
+}
+
+func (t *GoalsTx) Update(ctx context.Context, goal model.Goal) error {
+	goal.Updated = t.goals.timeNow()
+	return t.storage.UpdateGoal(ctx, goal)
+}
+
+// Tx runs fn as a unit of work, goals changed with tx are stored only when fn succeeds, changes made with
+// the repository itself aren't part of it, plain files storage doesn't support transactions, so changes are
+// applied one by one there
+func (r *Goals) Tx(ctx context.Context, fn func(tx *GoalsTx) error) error {
+	return r.storage.Tx(ctx, func(tx storage.GoalsTx) error { return fn(&GoalsTx{goals: r, storage: tx}) })
+}
+
+// Delete moves the goal to the trash, it could be restored with "termonizer trash restore"
+func (r *Goals) Delete(ctx context.Context, goal model.Goal) error {
+	return r.storage.TrashGoal(ctx, goal.ID, r.timeNow())
+}
+
+// Revisions returns the history of the goal from the oldest
+func (r *Goals) Revisions(ctx context.Context, goalID string) ([]model.Revision, error) {
+	revisions, err := r.storage.ReadRevisions(ctx, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// Search finds goals containing the query, the newest first
+func (r *Goals) Search(ctx context.Context, query string) ([]model.Goal, error) {
+	goals, err := r.storage.SearchGoals(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to search goals: %w", err)
+	}
+
+	slices.SortStableFunc(goals, func(a, b model.Goal) int { return b.Start.Compare(a.Start) })
+
+	return goals, nil
+}
@@ -1,22 +1,74 @@
 package repository
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/storage"
+	"github.com/nvbn/termonizer/internal/todotxt"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
 
-type goalsStorageMock struct{}
+type goalsStorageMock struct {
+	goals  []model.Goal
+	failID string
+}
 
 func (m *goalsStorageMock) ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error) {
-	return make([]model.Goal, 0), nil
+	goals := make([]model.Goal, 0)
+	for _, goal := range m.goals {
+		if goal.Period == period {
+			goals = append(goals, goal)
+		}
+	}
+
+	return goals, nil
+}
+
+func (m *goalsStorageMock) UpdateGoal(ctx context.Context, goal model.Goal) error {
+	if goal.ID == m.failID {
+		return errors.New("failed to update")
+	}
+
+	for n := range m.goals {
+		if m.goals[n].ID == goal.ID {
+			m.goals[n] = goal
+			return nil
+		}
+	}
+
+	m.goals = append(m.goals, goal)
+	return nil
 }
 
-func (m *goalsStorageMock) UpdateGoal(ctx context.Context, goals model.Goal) error {
+// Tx restores goals when fn fails
+func (m *goalsStorageMock) Tx(ctx context.Context, fn func(tx storage.GoalsTx) error) error {
+	goals := slices.Clone(m.goals)
+	if err := fn(m); err != nil {
+		m.goals = goals
+		return err
+	}
+
 	return nil
 }
 
+func (m *goalsStorageMock) TrashGoal(ctx context.Context, id string, deleted time.Time) error {
+	return nil
+}
+
+func (m *goalsStorageMock) SearchGoals(ctx context.Context, query string) ([]model.Goal, error) {
+	return make([]model.Goal, 0), nil
+}
+
+func (m *goalsStorageMock) ReadRevisions(ctx context.Context, goalID string) ([]model.Revision, error) {
+	return make([]model.Revision, 0), nil
+}
+
 func (m *goalsStorageMock) CountGoalsForPeriod(ctx context.Context, period int) (int, error) {
 	return 0, nil
 }
@@ -29,7 +81,9 @@ func TestGoalsRepository_FindForPeriod_Padding(t *testing.T) {
 		func() time.Time {
 			return time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
 		},
-		&goalsStorageMock{})
+		&goalsStorageMock{},
+		&Templates{},
+		&recurringStorageMock{})
 
 	periodToExpectedGoalTitle := map[model.Period][]string{
 		model.Year:    {"2025", "2024"},
@@ -59,3 +113,288 @@ func TestGoalsRepository_FindForPeriod_Padding(t *testing.T) {
 		})
 	}
 }
+
+func TestGoalsRepository_FindForPeriod_Templates(t *testing.T) {
+	ctx := t.Context()
+
+	templates, err := NewTemplates(map[model.Period]string{
+		model.Day:  "# {{weekday}} {{date}}\n* ",
+		model.Week: "W{{week}} of {{year}} Q{{quarter}} from {{date \"Jan 2\"}}",
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	r := NewGoalsRepository(
+		func() time.Time {
+			return time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+		},
+		&goalsStorageMock{},
+		templates,
+		&recurringStorageMock{})
+
+	periodToExpectedContent := map[model.Period][]string{
+		model.Year: {"", ""},
+		model.Week: {"W51 of 2024 Q4 from Dec 16", "W50 of 2024 Q4 from Dec 9"},
+		model.Day:  {"# Wednesday 2024-12-11\n* ", "# Tuesday 2024-12-10\n* "},
+	}
+
+	for period, expectedContent := range periodToExpectedContent {
+		t.Run(model.PeriodName(period), func(t *testing.T) {
+			goals, err := r.FindForPeriod(ctx, period)
+			if err != nil {
+				t.Error("unexpected error:", err)
+			}
+
+			for n, goal := range goals {
+				if goal.Content != expectedContent[n] {
+					t.Errorf("expected content %q, got %q", expectedContent[n], goal.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestNewTemplates_Invalid(t *testing.T) {
+	if _, err := NewTemplates(map[model.Period]string{model.Day: "{{unknown}}"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestGoalsRepository_FindForPeriod_Recurring(t *testing.T) {
+	ctx := t.Context()
+
+	templates, err := NewTemplates(map[model.Period]string{model.Day: "# {{weekday}}\n"})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	r := NewGoalsRepository(
+		func() time.Time {
+			return time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+		},
+		&goalsStorageMock{},
+		templates,
+		&recurringStorageMock{items: []model.Recurring{
+			{Schedule: "daily", Content: "* check mail"},
+			{Schedule: "wednesday", Content: "* 1:1 prep"},
+			{Schedule: "weekly", Content: "* retro"},
+			{Schedule: "quarterly", Content: "* quarterly OKR review"},
+		}})
+
+	periodToExpectedContent := map[model.Period][]string{
+		model.Year:    {"", ""},
+		model.Quarter: {"* quarterly OKR review", "* quarterly OKR review"},
+		model.Week:    {"* retro", "* retro"},
+		model.Day:     {"# Wednesday\n* check mail\n* 1:1 prep", "# Tuesday\n* check mail"},
+	}
+
+	for period, expectedContent := range periodToExpectedContent {
+		t.Run(model.PeriodName(period), func(t *testing.T) {
+			goals, err := r.FindForPeriod(ctx, period)
+			if err != nil {
+				t.Error("unexpected error:", err)
+			}
+
+			for n, goal := range goals {
+				if goal.Content != expectedContent[n] {
+					t.Errorf("expected content %q, got %q", expectedContent[n], goal.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestGoalsRepository_Find(t *testing.T) {
+	ctx := t.Context()
+
+	r := NewGoalsRepository(
+		time.Now,
+		&goalsStorageMock{goals: []model.Goal{
+			{ID: "year", Period: model.Year, Content: "* year"},
+			{ID: "day", Period: model.Day, Content: "* day"},
+		}},
+		&Templates{},
+		&recurringStorageMock{})
+
+	goals, err := r.All(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(goals) != 2 {
+		t.Errorf("expected 2 goals, got %v", goals)
+	}
+
+	goal, err := r.Find(ctx, "day")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if goal.Content != "* day" {
+		t.Errorf("expected day goal, got %v", goal)
+	}
+
+	if _, err := r.Find(ctx, "week"); !errors.Is(err, ErrGoalNotFound) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestGoalsRepository_UpdateMany(t *testing.T) {
+	ctx := t.Context()
+
+	now := time.Date(2024, 12, 10, 12, 0, 0, 0, time.Local)
+	storage := &goalsStorageMock{goals: []model.Goal{{ID: "week", Period: model.Week, Content: "* [ ] item"}}}
+	r := NewGoalsRepository(func() time.Time { return now }, storage, &Templates{}, &recurringStorageMock{})
+
+	err := r.UpdateMany(ctx, []model.Goal{
+		{ID: "week", Period: model.Week, Content: ""},
+		{ID: "day", Period: model.Day, Content: "* [ ] item"},
+	})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	expected := []model.Goal{
+		{ID: "week", Period: model.Week, Content: "", Updated: now},
+		{ID: "day", Period: model.Day, Content: "* [ ] item", Updated: now},
+	}
+	if !reflect.DeepEqual(expected, storage.goals) {
+		t.Errorf("expected %v, got %v", expected, storage.goals)
+	}
+}
+
+func TestGoalsRepository_Tx(t *testing.T) {
+	ctx := t.Context()
+
+	storage := &goalsStorageMock{goals: []model.Goal{{ID: "week", Period: model.Week, Content: "* [ ] item"}}, failID: "broken"}
+	r := NewGoalsRepository(time.Now, storage, &Templates{}, &recurringStorageMock{})
+
+	err := r.UpdateMany(ctx, []model.Goal{
+		{ID: "week", Period: model.Week, Content: ""},
+		{ID: "broken", Period: model.Day, Content: "* [ ] item"},
+	})
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	if len(storage.goals) != 1 || storage.goals[0].Content != "* [ ] item" {
+		t.Errorf("expected goals to be unchanged, got %v", storage.goals)
+	}
+
+	changed, err := r.Import(ctx, []model.Goal{
+		{ID: "day", Period: model.Day, Content: "* imported"},
+		{ID: "broken", Period: model.Year, Content: "* broken"},
+	})
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	if changed != 0 || len(storage.goals) != 1 {
+		t.Errorf("expected nothing to be imported, got %d: %v", changed, storage.goals)
+	}
+
+	failed := errors.New("failed")
+	err = r.Tx(ctx, func(tx *GoalsTx) error {
+		if err := tx.Update(ctx, model.Goal{ID: "week", Period: model.Week, Content: "* [x] item"}); err != nil {
+			return err
+		}
+
+		// changes are visible inside the unit of work
+		goals, err := tx.FindForPeriod(ctx, model.Week)
+		if err != nil {
+			return err
+		}
+
+		if !slices.ContainsFunc(goals, func(goal model.Goal) bool { return goal.Content == "* [x] item" }) {
+			t.Errorf("expected the changed goal, got %v", goals)
+		}
+
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("expected %v, got %v", failed, err)
+	}
+
+	if len(storage.goals) != 1 || storage.goals[0].Content != "* [ ] item" {
+		t.Errorf("expected goals to be unchanged, got %v", storage.goals)
+	}
+}
+
+func TestGoalsRepository_Import(t *testing.T) {
+	ctx := t.Context()
+
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)
+	storage := &goalsStorageMock{goals: []model.Goal{
+		{ID: "week", Period: model.Week, Start: start, Content: "* [ ] plan"},
+		{ID: "day", Period: model.Day, Start: start, Content: "* [ ] call Bob"},
+	}}
+
+	r := NewGoalsRepository(time.Now, storage, &Templates{}, &recurringStorageMock{})
+
+	changed, err := r.Import(ctx, []model.Goal{
+		{ID: "week", Period: model.Week, Start: start, Content: "* [x] plan"},
+		{ID: "day", Period: model.Day, Start: start, Content: "* [ ] call Bob"},
+		{ID: "other-day", Period: model.Day, Start: start, Content: "* [ ] buy milk"},
+		{ID: "another-day", Period: model.Day, Start: start, Content: "* [ ] buy milk"},
+		{ID: "year", Period: model.Year, Start: start, Content: "* ship it"},
+	})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if changed != 3 {
+		t.Errorf("expected 3 changed goals, got %d", changed)
+	}
+
+	idToContent := make(map[string]string)
+	for _, goal := range storage.goals {
+		idToContent[goal.ID] = goal.Content
+	}
+
+	expected := map[string]string{
+		"week": "* [x] plan",
+		"day":  "* [ ] call Bob\n* [ ] buy milk",
+		"year": "* ship it",
+	}
+	if !reflect.DeepEqual(expected, idToContent) {
+		t.Errorf("expected %v, got %v", expected, idToContent)
+	}
+}
+
+func TestGoalsRepository_ImportChecklists_Export(t *testing.T) {
+	ctx := t.Context()
+
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)
+	storage := &goalsStorageMock{goals: []model.Goal{
+		{ID: "day", Period: model.Day, Start: start, Content: "Notes\n# Work\n* [ ] a\n* [ ] b"},
+	}}
+
+	r := NewGoalsRepository(time.Now, storage, &Templates{}, &recurringStorageMock{})
+
+	var exported bytes.Buffer
+	if err := todotxt.Write(&exported, storage.goals); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	// checked in a todo.txt app
+	edited := strings.Replace(exported.String(), start.Format("2006-01-02")+" b", "x 2024-12-09 "+start.Format("2006-01-02")+" b", 1)
+	imported, err := todotxt.Read(strings.NewReader(edited+"new item goal:day\n"), start)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	changed, err := r.ImportChecklists(ctx, imported)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if changed != 1 {
+		t.Errorf("expected 1 changed goal, got %d", changed)
+	}
+
+	expected := "Notes\n# Work\n* [ ] a\n* [x] b\n* [ ] new item"
+	if len(storage.goals) != 1 || storage.goals[0].Content != expected {
+		t.Errorf("expected %q, got %v", expected, storage.goals)
+	}
+}
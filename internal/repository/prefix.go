@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// findByPrefix finds the only item with id starting with the prefix, so short ids could be used in commands
+func findByPrefix[T any](items []T, getID func(T) string, kind string, prefix string) (T, error) {
+	found := make([]T, 0)
+	for _, item := range items {
+		if strings.HasPrefix(getID(item), prefix) {
+			found = append(found, item)
+		}
+	}
+
+	var empty T
+	if len(found) == 0 {
+		return empty, fmt.Errorf("%s %s not found", kind, prefix)
+	} else if len(found) > 1 {
+		return empty, fmt.Errorf("%s %s is ambiguous", kind, prefix)
+	}
+
+	return found[0], nil
+}
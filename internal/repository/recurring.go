@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"strings"
+	"time"
+)
+
+type recurringStorage interface {
+	ReadRecurring(ctx context.Context) ([]model.Recurring, error)
+	UpdateRecurring(ctx context.Context, recurring model.Recurring) error
+	DeleteRecurring(ctx context.Context, id string) error
+}
+
+type Recurring struct {
+	timeNow func() time.Time
+	storage recurringStorage
+}
+
+func NewRecurring(timeNow func() time.Time, storage recurringStorage) *Recurring {
+	return &Recurring{
+		timeNow: timeNow,
+		storage: storage,
+	}
+}
+
+func (r *Recurring) List(ctx context.Context) ([]model.Recurring, error) {
+	return r.storage.ReadRecurring(ctx)
+}
+
+func (r *Recurring) Add(ctx context.Context, schedule string, content string) (model.Recurring, error) {
+	recurring, err := model.NewRecurring(strings.ToLower(schedule), content, r.timeNow())
+	if err != nil {
+		return model.Recurring{}, err
+	}
+
+	if err := r.storage.UpdateRecurring(ctx, recurring); err != nil {
+		return model.Recurring{}, fmt.Errorf("unable to add recurring: %w", err)
+	}
+
+	return recurring, nil
+}
+
+// Remove deletes the recurring item by id or its unique prefix
+func (r *Recurring) Remove(ctx context.Context, id string) error {
+	items, err := r.storage.ReadRecurring(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to read recurring: %w", err)
+	}
+
+	found, err := findByPrefix(items, func(item model.Recurring) string { return item.ID }, "recurring", id)
+	if err != nil {
+		return err
+	}
+
+	return r.storage.DeleteRecurring(ctx, found.ID)
+}
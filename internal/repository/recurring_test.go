@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+type recurringStorageMock struct {
+	items []model.Recurring
+}
+
+func (m *recurringStorageMock) ReadRecurring(ctx context.Context) ([]model.Recurring, error) {
+	return m.items, nil
+}
+
+func (m *recurringStorageMock) UpdateRecurring(ctx context.Context, recurring model.Recurring) error {
+	m.items = append(m.items, recurring)
+	return nil
+}
+
+func (m *recurringStorageMock) DeleteRecurring(ctx context.Context, id string) error {
+	m.items = slices.DeleteFunc(m.items, func(item model.Recurring) bool { return item.ID == id })
+	return nil
+}
+
+func TestRecurring_AddRemove(t *testing.T) {
+	ctx := t.Context()
+	storage := &recurringStorageMock{}
+	r := NewRecurring(time.Now, storage)
+
+	if _, err := r.Add(ctx, "someday", "* never"); err == nil {
+		t.Error("expected error for invalid schedule")
+	}
+
+	added, err := r.Add(ctx, "Monday", "* weekly 1:1 prep")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if added.Schedule != "monday" {
+		t.Errorf("expected normalized schedule, got %s", added.Schedule)
+	}
+
+	items, err := r.List(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(items) != 1 {
+		t.Errorf("expected 1 item, got %d", len(items))
+	}
+
+	if err := r.Remove(ctx, "not-an-id"); err == nil {
+		t.Error("expected error for unknown id")
+	}
+
+	if err := r.Remove(ctx, added.ID[:8]); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(storage.items) != 0 {
+		t.Errorf("expected 0 items, got %d", len(storage.items))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/nvbn/termonizer/internal/model"
 	"log"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -18,6 +19,10 @@ var defaultPeriodToAmount = map[model.Period]int{
 
 const periodToAmountPrefix = "period_to_amount_"
 
+const markdownPreviewKey = "markdown_preview"
+
+const layoutKey = "layout"
+
 type settingsStore interface {
 	ReadSettings(ctx context.Context) ([]model.Setting, error)
 	UpdateSetting(ctx context.Context, setting model.Setting) error
@@ -27,7 +32,9 @@ type Settings struct {
 	timeNow func() time.Time
 	storage settingsStore
 
-	periodToAmount map[model.Period]int
+	periodToAmount  map[model.Period]int
+	markdownPreview bool
+	layout          model.Layout
 }
 
 func NewSettings(ctx context.Context, timeNow func() time.Time, storage settingsStore) (*Settings, error) {
@@ -35,6 +42,7 @@ func NewSettings(ctx context.Context, timeNow func() time.Time, storage settings
 		timeNow:        timeNow,
 		storage:        storage,
 		periodToAmount: defaultPeriodToAmount,
+		layout:         model.LayoutAuto,
 	}
 
 	if err := s.init(ctx); err != nil {
@@ -69,6 +77,23 @@ func (s *Settings) init(ctx context.Context) error {
 		}
 	}
 
+	if value, ok := kvLowLevel[markdownPreviewKey]; ok {
+		boolValue, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("invalid setting %s value %s", markdownPreviewKey, value)
+		} else {
+			s.markdownPreview = boolValue
+		}
+	}
+
+	if value, ok := kvLowLevel[layoutKey]; ok {
+		if slices.Contains(model.Layouts, model.Layout(value)) {
+			s.layout = model.Layout(value)
+		} else {
+			log.Printf("invalid setting %s value %s", layoutKey, value)
+		}
+	}
+
 	return nil
 }
 
@@ -89,3 +114,39 @@ func (s *Settings) SetAmountForPeriod(ctx context.Context, period model.Period,
 
 	return nil
 }
+
+func (s *Settings) GetMarkdownPreview() bool {
+	return s.markdownPreview
+}
+
+func (s *Settings) SetMarkdownPreview(ctx context.Context, enabled bool) error {
+	s.markdownPreview = enabled
+
+	if err := s.storage.UpdateSetting(ctx, model.Setting{
+		ID:      markdownPreviewKey,
+		Value:   strconv.FormatBool(enabled),
+		Updated: s.timeNow(),
+	}); err != nil {
+		return fmt.Errorf("unable to update setting: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Settings) GetLayout() model.Layout {
+	return s.layout
+}
+
+func (s *Settings) SetLayout(ctx context.Context, layout model.Layout) error {
+	s.layout = layout
+
+	if err := s.storage.UpdateSetting(ctx, model.Setting{
+		ID:      layoutKey,
+		Value:   string(layout),
+		Updated: s.timeNow(),
+	}); err != nil {
+		return fmt.Errorf("unable to update setting: %w", err)
+	}
+
+	return nil
+}
@@ -13,6 +13,8 @@ type settingsStorageMock struct{}
 func (s *settingsStorageMock) ReadSettings(ctx context.Context) ([]model.Setting, error) {
 	return []model.Setting{
 		{ID: fmt.Sprintf("period_to_amount_%d", model.Week), Value: "12"},
+		{ID: "markdown_preview", Value: "true"},
+		{ID: "layout", Value: "rows"},
 	}, nil
 }
 
@@ -38,3 +40,45 @@ func TestSettings_GetAmountForPeriod(t *testing.T) {
 		t.Errorf("expected %d, got %d", expectedQuarter, s.GetAmountForPeriod(model.Quarter))
 	}
 }
+
+func TestSettings_MarkdownPreview(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := NewSettings(ctx, time.Now, &settingsStorageMock{})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if !s.GetMarkdownPreview() {
+		t.Errorf("expected markdown preview to be enabled")
+	}
+
+	if err := s.SetMarkdownPreview(ctx, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if s.GetMarkdownPreview() {
+		t.Errorf("expected markdown preview to be disabled")
+	}
+}
+
+func TestSettings_Layout(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := NewSettings(ctx, time.Now, &settingsStorageMock{})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if s.GetLayout() != model.LayoutRows {
+		t.Errorf("expected rows layout, got %s", s.GetLayout())
+	}
+
+	if err := s.SetLayout(ctx, model.LayoutTabs); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if s.GetLayout() != model.LayoutTabs {
+		t.Errorf("expected tabs layout, got %s", s.GetLayout())
+	}
+}
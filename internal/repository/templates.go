@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/utils"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Templates pre-fill new goals, available variables:
+//
+//	{{date}} or {{date "Jan 2"}} - start of the goal, 2006-01-02 by default
+//	{{weekday}} - Monday, {{week}} - 50, {{quarter}} - 4, {{year}} - 2024
+type Templates struct {
+	templates map[model.Period]*template.Template
+}
+
+func NewTemplates(sources map[model.Period]string) (*Templates, error) {
+	t := &Templates{templates: make(map[model.Period]*template.Template)}
+
+	for period, source := range sources {
+		parsed, err := template.New(model.PeriodName(period)).Funcs(templateFuncs(time.Time{})).Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %s: %w", model.PeriodName(period), err)
+		}
+
+		t.templates[period] = parsed
+	}
+
+	return t, nil
+}
+
+func templateFuncs(start time.Time) template.FuncMap {
+	return template.FuncMap{
+		"date": func(layout ...string) string {
+			if len(layout) > 0 {
+				return start.Format(layout[0])
+			}
+			return start.Format("2006-01-02")
+		},
+		"weekday": func() string { return start.Weekday().String() },
+		"week":    func() int { return utils.WeekNumber(start) },
+		"quarter": func() int { return utils.QuarterFromTime(start) },
+		"year":    func() int { return start.Year() },
+	}
+}
+
+// Render returns the content for a new goal, empty when there's no template for the period
+func (t *Templates) Render(goal model.Goal) string {
+	tmpl, ok := t.templates[goal.Period]
+	if !ok {
+		return ""
+	}
+
+	clone, err := tmpl.Clone()
+	if err != nil {
+		log.Printf("failed to clone template: %v", err)
+		return ""
+	}
+
+	var out strings.Builder
+	if err := clone.Funcs(templateFuncs(goal.Start)).Execute(&out, nil); err != nil {
+		log.Printf("failed to render template: %v", err)
+		return ""
+	}
+
+	return out.String()
+}
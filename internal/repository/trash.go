@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+)
+
+type trashStorage interface {
+	ReadTrash(ctx context.Context) ([]model.TrashedGoal, error)
+	RestoreGoal(ctx context.Context, id string) error
+	PurgeTrashed(ctx context.Context, id string) error
+	ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error)
+}
+
+type Trash struct {
+	storage trashStorage
+}
+
+func NewTrash(storage trashStorage) *Trash {
+	return &Trash{
+		storage: storage,
+	}
+}
+
+func (r *Trash) List(ctx context.Context) ([]model.TrashedGoal, error) {
+	return r.storage.ReadTrash(ctx)
+}
+
+func (r *Trash) find(ctx context.Context, id string) (model.TrashedGoal, error) {
+	trashed, err := r.storage.ReadTrash(ctx)
+	if err != nil {
+		return model.TrashedGoal{}, fmt.Errorf("unable to read trash: %w", err)
+	}
+
+	return findByPrefix(trashed, func(goal model.TrashedGoal) string { return goal.ID }, "trashed goal", id)
+}
+
+// Restore moves the goal back by id or its unique prefix, fails when the period already has a non-empty goal
+func (r *Trash) Restore(ctx context.Context, id string) (model.TrashedGoal, error) {
+	trashed, err := r.find(ctx, id)
+	if err != nil {
+		return model.TrashedGoal{}, err
+	}
+
+	goals, err := r.storage.ReadGoalsForPeriod(ctx, trashed.Period)
+	if err != nil {
+		return model.TrashedGoal{}, fmt.Errorf("unable to read goals: %w", err)
+	}
+
+	for _, goal := range goals {
+		if goal.CompareStart(trashed.Start) == 0 {
+			return model.TrashedGoal{}, fmt.Errorf("%s %s already has a goal, clear it first", model.PeriodName(goal.Period), goal.FormatStart())
+		}
+	}
+
+	if err := r.storage.RestoreGoal(ctx, trashed.ID); err != nil {
+		return model.TrashedGoal{}, fmt.Errorf("unable to restore goal: %w", err)
+	}
+
+	return trashed, nil
+}
+
+// Purge removes the goal from the trash for good, all goals when id is empty
+func (r *Trash) Purge(ctx context.Context, id string) error {
+	if id != "" {
+		trashed, err := r.find(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		return r.storage.PurgeTrashed(ctx, trashed.ID)
+	}
+
+	trashed, err := r.storage.ReadTrash(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to read trash: %w", err)
+	}
+
+	for _, goal := range trashed {
+		if err := r.storage.PurgeTrashed(ctx, goal.ID); err != nil {
+			return fmt.Errorf("unable to purge goal: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+type trashStorageMock struct {
+	goals   []model.Goal
+	trashed []model.TrashedGoal
+}
+
+func (m *trashStorageMock) ReadTrash(ctx context.Context) ([]model.TrashedGoal, error) {
+	return m.trashed, nil
+}
+
+func (m *trashStorageMock) RestoreGoal(ctx context.Context, id string) error {
+	for _, goal := range m.trashed {
+		if goal.ID == id {
+			m.goals = append(m.goals, goal.Goal)
+		}
+	}
+	return m.PurgeTrashed(ctx, id)
+}
+
+func (m *trashStorageMock) PurgeTrashed(ctx context.Context, id string) error {
+	m.trashed = slices.DeleteFunc(m.trashed, func(goal model.TrashedGoal) bool { return goal.ID == id })
+	return nil
+}
+
+func (m *trashStorageMock) ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error) {
+	return m.goals, nil
+}
+
+func TestTrash_Restore(t *testing.T) {
+	ctx := t.Context()
+	monday := time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)
+	tuesday := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+
+	storage := &trashStorageMock{
+		goals: []model.Goal{{ID: "existing", Period: model.Day, Content: "new", Start: tuesday}},
+		trashed: []model.TrashedGoal{
+			{Goal: model.Goal{ID: "aaa-1", Period: model.Day, Content: "old", Start: tuesday}},
+			{Goal: model.Goal{ID: "aaa-2", Period: model.Day, Content: "old", Start: monday}},
+		},
+	}
+	trash := NewTrash(storage)
+
+	if _, err := trash.Restore(ctx, "aaa"); err == nil {
+		t.Error("expected error for ambiguous id")
+	}
+
+	if _, err := trash.Restore(ctx, "aaa-1"); err == nil {
+		t.Error("expected error for the day with an existing goal")
+	}
+
+	restored, err := trash.Restore(ctx, "aaa-2")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if restored.ID != "aaa-2" || len(storage.goals) != 2 {
+		t.Errorf("expected goal to be restored, got %v", storage.goals)
+	}
+
+	if err := trash.Purge(ctx, ""); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(storage.trashed) != 0 {
+		t.Errorf("expected empty trash, got %v", storage.trashed)
+	}
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"strings"
+)
+
+const (
+	encryptedPrefix = "enc:v1:"
+	saltSize        = 16
+	verifierID      = "verifier"
+	verifierText    = "termonizer"
+)
+
+var (
+	ErrLocked          = errors.New("the database is encrypted, unlock it with the passphrase first")
+	ErrWrongPassphrase = errors.New("wrong passphrase")
+)
+
+// contentCipher encrypts content with AES-GCM, the row id is authenticated with it so values can't be swapped
+// between rows, nil cipher keeps values as is
+type contentCipher struct {
+	aead cipher.AEAD
+}
+
+func newContentCipher(passphrase string, salt []byte) (*contentCipher, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, 1, 64*1024, 4, 32)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init gcm: %w", err)
+	}
+
+	return &contentCipher{aead: aead}, nil
+}
+
+// encrypt keeps empty values empty, so queries filtering empty content still work
+func (c *contentCipher) encrypt(id string, plaintext string) (string, error) {
+	if c == nil || plaintext == "" {
+		return plaintext, nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(id))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt returns not encrypted values as is, they're left from before the encryption was enabled
+func (c *contentCipher) decrypt(id string, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	if c == nil {
+		return "", ErrLocked
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", fmt.Errorf("malformed encrypted value for %s", id)
+	}
+
+	nonceSize := c.aead.NonceSize()
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", id, err)
+	}
+
+	return string(plaintext), nil
+}
+
+func (s *SQLite) readEncryption(ctx context.Context) (salt []byte, verifier string, err error) {
+	err = s.db.QueryRowContext(ctx, `select salt, verifier from Encryption where id = 1`).Scan(&salt, &verifier)
+	return salt, verifier, err
+}
+
+func (s *SQLite) IsEncrypted(ctx context.Context) (bool, error) {
+	_, _, err := s.readEncryption(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read encryption: %w", err)
+	}
+
+	return true, nil
+}
+
+// Unlock derives the key from the passphrase, content is decrypted on read and encrypted on write after that
+func (s *SQLite) Unlock(ctx context.Context, passphrase string) error {
+	salt, verifier, err := s.readEncryption(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read encryption: %w", err)
+	}
+
+	c, err := newContentCipher(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	if plaintext, err := c.decrypt(verifierID, verifier); err != nil || plaintext != verifierText {
+		return ErrWrongPassphrase
+	}
+
+	s.cipher = c
+	return nil
+}
+
+// ChangePassphrase re-encrypts all content with a key derived from the new passphrase with a new salt,
+// empty passphrase disables the encryption, the database should be unlocked when it's encrypted
+func (s *SQLite) ChangePassphrase(ctx context.Context, passphrase string) error {
+	var next *contentCipher
+	salt := make([]byte, saltSize)
+	if passphrase != "" {
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+
+		var err error
+		if next, err = newContentCipher(passphrase, salt); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// id column is authenticated with the content
+	for _, encrypted := range []struct{ table, idColumn, column string }{
+		{"Goals", "id", "content"},
+		{"Goals", "id", "last_content"},
+		{"Trash", "id", "content"},
+		{"Recurring", "id", "content"},
+		{"Revisions", "goal_id", "content"},
+	} {
+		if err := s.reencrypt(ctx, tx, next, encrypted.table, encrypted.idColumn, encrypted.column); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `delete from Encryption`); err != nil {
+		return fmt.Errorf("failed to reset encryption: %w", err)
+	}
+
+	if next != nil {
+		verifier, err := next.encrypt(verifierID, verifierText)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `insert into Encryption (id, salt, verifier) values (1, ?, ?)`, salt, verifier); err != nil {
+			return fmt.Errorf("failed to save encryption: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	s.cipher = next
+	return nil
+}
+
+func (s *SQLite) reencrypt(ctx context.Context, tx *sql.Tx, next *contentCipher, table string, idColumn string, column string) error {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`select rowid, %s, %s from %s`, idColumn, column, table))
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", table, err)
+	}
+
+	type row struct {
+		rowID int64
+		id    string
+		value string
+	}
+
+	toUpdate := make([]row, 0)
+	for rows.Next() {
+		r := row{}
+		if err := rows.Scan(&r.rowID, &r.id, &r.value); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan %s: %w", table, err)
+		}
+		toUpdate = append(toUpdate, r)
+	}
+	rows.Close()
+
+	for _, r := range toUpdate {
+		plaintext, err := s.cipher.decrypt(r.id, r.value)
+		if err != nil {
+			return err
+		}
+
+		encrypted, err := next.encrypt(r.id, plaintext)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`update %s set %s = ? where rowid = ?`, table, column), encrypted, r.rowID); err != nil {
+			return fmt.Errorf("failed to update %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
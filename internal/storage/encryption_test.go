@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"errors"
+	"github.com/nvbn/termonizer/internal/model"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLite_Encryption(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "termonizer.db")
+
+	s, err := NewSQLite(ctx, path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	date := time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC)
+	goal := model.Goal{ID: "goal", Period: model.Day, Content: "* secret 1:1 notes", Start: date, Updated: date}
+	if err := s.UpdateGoal(ctx, goal); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if err := s.ChangePassphrase(ctx, "passphrase"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	var raw string
+	if err := s.db.QueryRowContext(ctx, `select content from Goals where id = ?`, goal.ID).Scan(&raw); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !strings.HasPrefix(raw, encryptedPrefix) || strings.Contains(raw, "secret") {
+		t.Errorf("expected encrypted content, got %s", raw)
+	}
+	s.Close()
+
+	s, err = NewSQLite(ctx, path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.Close()
+
+	if _, err := s.ReadGoalsForPeriod(ctx, model.Day); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected locked error, got %v", err)
+	}
+
+	if err := s.Unlock(ctx, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected wrong passphrase error, got %v", err)
+	}
+
+	if err := s.Unlock(ctx, "passphrase"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	found, err := s.SearchGoals(ctx, "SECRET")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(found) != 1 || found[0].Content != goal.Content {
+		t.Errorf("expected decrypted goal, got %v", found)
+	}
+
+	if err := s.ChangePassphrase(ctx, "rotated"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := s.Unlock(ctx, "passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("expected the old passphrase to stop working, got %v", err)
+	}
+
+	if err := s.Unlock(ctx, "rotated"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := s.ChangePassphrase(ctx, ""); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if encrypted, err := s.IsEncrypted(ctx); err != nil || encrypted {
+		t.Errorf("expected decrypted database, got %v %v", encrypted, err)
+	}
+
+	if err := s.db.QueryRowContext(ctx, `select content from Goals where id = ?`, goal.ID).Scan(&raw); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if raw != goal.Content {
+		t.Errorf("expected plaintext content, got %s", raw)
+	}
+}
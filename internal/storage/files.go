@@ -0,0 +1,562 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/utils"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filesTrashDir             = ".trash"
+	filesClearedDir           = ".trash/.cleared" // the last non-empty content of cleared goals until vacuum
+	filesSettingsFile         = "settings.toml"
+	filesRecurringFile        = "recurring.toml"
+	filesDeletedLayout        = "20060102T150405.000000000" // nanoseconds keep names unique
+	filesDeletedLayoutSeconds = "20060102T150405"           // trashed before nanoseconds were added to names
+	filesDeletedDivider       = "~"
+)
+
+// Files keeps every goal in a markdown file named after its period and start, like year/2024.md,
+// quarter/2024-Q4.md, week/2024-W50.md or day/2024-12-10.md, so notes could be kept in git and edited outside.
+// Files don't store ids, a goal id is its path without the extension, ids of goals created during the session
+// are remembered so editors keep their state.
+type Files struct {
+	dir string
+
+	mu       sync.Mutex
+	pathToID map[string]string
+}
+
+func NewFiles(dir string) (*Files, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create goals dir: %w", err)
+	}
+
+	return &Files{
+		dir:      dir,
+		pathToID: make(map[string]string),
+	}, nil
+}
+
+func (f *Files) Dir() string {
+	return f.dir
+}
+
+func periodDir(period model.Period) string {
+	return strings.ToLower(model.PeriodName(period))
+}
+
+// goalName is the file name without the extension
+func goalName(period model.Period, start time.Time) string {
+	switch period {
+	case model.Year:
+		return start.Format("2006")
+	case model.Quarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), utils.QuarterFromTime(start))
+	case model.Week:
+		weekStart := utils.WeekStart(start)
+		toMonday := (int(time.Monday) - int(weekStart.Weekday()) + 7) % 7
+		year, week := weekStart.AddDate(0, 0, toMonday).ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return start.Format("2006-01-02")
+	}
+}
+
+func parseGoalName(period model.Period, name string) (time.Time, error) {
+	switch period {
+	case model.Year:
+		return time.ParseInLocation("2006", name, time.Local)
+	case model.Quarter:
+		year, quarter, ok := strings.Cut(name, "-Q")
+		yearNumber, yearErr := strconv.Atoi(year)
+		quarterNumber, quarterErr := strconv.Atoi(quarter)
+		if !ok || yearErr != nil || quarterErr != nil || quarterNumber < 1 || quarterNumber > 4 {
+			return time.Time{}, fmt.Errorf("invalid quarter %s", name)
+		}
+		return time.Date(yearNumber, time.Month((quarterNumber-1)*3+1), 1, 0, 0, 0, 0, time.Local), nil
+	case model.Week:
+		year, week, ok := strings.Cut(name, "-W")
+		yearNumber, yearErr := strconv.Atoi(year)
+		weekNumber, weekErr := strconv.Atoi(week)
+		if !ok || yearErr != nil || weekErr != nil || weekNumber < 1 || weekNumber > 53 {
+			return time.Time{}, fmt.Errorf("invalid week %s", name)
+		}
+		// the first iso week contains the 4th of january
+		jan4 := time.Date(yearNumber, time.January, 4, 0, 0, 0, 0, time.Local)
+		firstMonday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+		return utils.WeekStart(firstMonday.AddDate(0, 0, (weekNumber-1)*7)), nil
+	default:
+		return time.ParseInLocation("2006-01-02", name, time.Local)
+	}
+}
+
+func goalPath(period model.Period, start time.Time) string {
+	return filepath.Join(periodDir(period), goalName(period, start)+".md")
+}
+
+func (f *Files) readGoal(period model.Period, entry fs.DirEntry) (model.Goal, bool, error) {
+	name, ok := strings.CutSuffix(entry.Name(), ".md")
+	if !ok || entry.IsDir() {
+		return model.Goal{}, false, nil
+	}
+
+	start, err := parseGoalName(period, name)
+	if err != nil {
+		// unrelated files are allowed next to goals
+		return model.Goal{}, false, nil
+	}
+
+	path := filepath.Join(periodDir(period), entry.Name())
+	content, err := os.ReadFile(filepath.Join(f.dir, path))
+	if err != nil {
+		return model.Goal{}, false, fmt.Errorf("failed to read goal: %w", err)
+	}
+
+	info, err := entry.Info()
+	if err != nil {
+		return model.Goal{}, false, fmt.Errorf("failed to stat goal: %w", err)
+	}
+
+	f.mu.Lock()
+	id, ok := f.pathToID[path]
+	f.mu.Unlock()
+	if !ok {
+		id = strings.TrimSuffix(path, ".md")
+	}
+
+	return model.Goal{
+		ID:      id,
+		Period:  period,
+		Content: string(content),
+		Start:   start,
+		Updated: info.ModTime(),
+	}, true, nil
+}
+
+func (f *Files) ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error) {
+	entries, err := os.ReadDir(filepath.Join(f.dir, periodDir(period)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return make([]model.Goal, 0), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read goals: %w", err)
+	}
+
+	result := make([]model.Goal, 0, len(entries))
+	for _, entry := range entries {
+		goal, ok, err := f.readGoal(period, entry)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok && goal.Content != "" {
+			result = append(result, goal)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b model.Goal) int { return b.Start.Compare(a.Start) })
+
+	return result, nil
+}
+
+func (f *Files) CountGoalsForPeriod(ctx context.Context, period int) (int, error) {
+	goals, err := f.ReadGoalsForPeriod(ctx, period)
+	return len(goals), err
+}
+
+func (f *Files) SearchGoals(ctx context.Context, query string) ([]model.Goal, error) {
+	result := make([]model.Goal, 0)
+	for _, period := range model.Periods {
+		goals, err := f.ReadGoalsForPeriod(ctx, period)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, goal := range goals {
+			if strings.Contains(strings.ToLower(goal.Content), strings.ToLower(query)) {
+				result = append(result, goal)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateGoal writes the goal file, a cleared goal keeps its last non-empty content aside, so vacuum could trash it
+func (f *Files) UpdateGoal(ctx context.Context, goal model.Goal) error {
+	path := goalPath(goal.Period, goal.Start)
+
+	f.mu.Lock()
+	f.pathToID[path] = goal.ID
+	f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Join(f.dir, periodDir(goal.Period)), 0700); err != nil {
+		return fmt.Errorf("failed to create period dir: %w", err)
+	}
+
+	cleared := filepath.Join(f.dir, filesClearedDir, path)
+	if goal.Content == "" {
+		previous, err := os.ReadFile(filepath.Join(f.dir, path))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read goal: %w", err)
+		}
+
+		if len(previous) > 0 {
+			if err := os.MkdirAll(filepath.Dir(cleared), 0700); err != nil {
+				return fmt.Errorf("failed to create trash dir: %w", err)
+			}
+
+			if err := writeFileAtomic(cleared, previous); err != nil {
+				return err
+			}
+		}
+	} else if err := os.Remove(cleared); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove cleared content: %w", err)
+	}
+
+	return writeFileAtomic(filepath.Join(f.dir, path), []byte(goal.Content))
+}
+
+// Tx just runs fn with the storage itself, every file is replaced atomically but there are no transactions between files
+func (f *Files) Tx(ctx context.Context, fn func(tx GoalsTx) error) error {
+	return fn(f)
+}
+
+// writeFileAtomic writes to a temporary file first, so other tools never see a half written file
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (f *Files) pathForID(id string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for path, pathID := range f.pathToID {
+		if pathID == id {
+			return path, nil
+		}
+	}
+
+	path := id + ".md"
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("invalid goal id %s", id)
+	}
+
+	return path, nil
+}
+
+// ReadRevisions returns nothing as files are versioned with git, see gitsync
+func (f *Files) ReadRevisions(ctx context.Context, goalID string) ([]model.Revision, error) {
+	return make([]model.Revision, 0), nil
+}
+
+func (f *Files) TrashGoal(ctx context.Context, id string, deleted time.Time) error {
+	path, err := f.pathForID(id)
+	if err != nil {
+		return err
+	}
+
+	return f.trashPath(path, deleted)
+}
+
+// trashPath moves the goal file to .trash/<period>/<name>~<deleted>.md, a cleared goal is trashed with its last
+// non-empty content
+func (f *Files) trashPath(path string, deleted time.Time) error {
+	content, err := os.ReadFile(filepath.Join(f.dir, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read goal: %w", err)
+	}
+
+	if len(content) == 0 {
+		cleared := filepath.Join(f.dir, filesClearedDir, path)
+		if _, err := os.Stat(cleared); err == nil {
+			if err := f.moveToTrash(cleared, path, deleted); err != nil {
+				return err
+			}
+		}
+
+		return os.Remove(filepath.Join(f.dir, path))
+	}
+
+	return f.moveToTrash(filepath.Join(f.dir, path), path, deleted)
+}
+
+// moveToTrash moves the file to the trash under the goal path, names are unique, so a trashed version
+// is never replaced by another one
+func (f *Files) moveToTrash(from string, path string, deleted time.Time) error {
+	var trashed string
+	for {
+		trashed = filepath.Join(f.dir, filesTrashDir, strings.TrimSuffix(path, ".md")+filesDeletedDivider+deleted.Format(filesDeletedLayout)+".md")
+		if _, err := os.Stat(trashed); errors.Is(err, fs.ErrNotExist) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to stat trashed goal: %w", err)
+		}
+
+		deleted = deleted.Add(time.Nanosecond)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(trashed), 0700); err != nil {
+		return fmt.Errorf("failed to create trash dir: %w", err)
+	}
+
+	return os.Rename(from, trashed)
+}
+
+func (f *Files) ReadTrash(ctx context.Context) ([]model.TrashedGoal, error) {
+	result := make([]model.TrashedGoal, 0)
+	for _, period := range model.Periods {
+		entries, err := os.ReadDir(filepath.Join(f.dir, filesTrashDir, periodDir(period)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read trash: %w", err)
+		}
+
+		for _, entry := range entries {
+			id := filepath.Join(periodDir(period), strings.TrimSuffix(entry.Name(), ".md"))
+			name, deletedAt, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".md"), filesDeletedDivider)
+			if !ok {
+				continue
+			}
+
+			start, err := parseGoalName(period, name)
+			if err != nil {
+				continue
+			}
+
+			deleted, err := time.ParseInLocation(filesDeletedLayout, deletedAt, time.Local)
+			if err != nil {
+				deleted, err = time.ParseInLocation(filesDeletedLayoutSeconds, deletedAt, time.Local)
+			}
+			if err != nil {
+				continue
+			}
+
+			content, err := os.ReadFile(filepath.Join(f.dir, filesTrashDir, id+".md"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read trashed goal: %w", err)
+			}
+
+			result = append(result, model.TrashedGoal{
+				Goal: model.Goal{
+					ID:      id,
+					Period:  period,
+					Content: string(content),
+					Start:   start,
+					Updated: deleted,
+				},
+				Deleted: deleted,
+			})
+		}
+	}
+
+	slices.SortFunc(result, func(a, b model.TrashedGoal) int { return b.Deleted.Compare(a.Deleted) })
+
+	return result, nil
+}
+
+func (f *Files) RestoreGoal(ctx context.Context, id string) error {
+	trashed, err := f.ReadTrash(ctx)
+	if err != nil {
+		return err
+	}
+
+	index := slices.IndexFunc(trashed, func(goal model.TrashedGoal) bool { return goal.ID == id })
+	if index == -1 {
+		return fmt.Errorf("trashed goal %s not found", id)
+	}
+
+	goal := trashed[index]
+	if err := os.MkdirAll(filepath.Join(f.dir, periodDir(goal.Period)), 0700); err != nil {
+		return fmt.Errorf("failed to create period dir: %w", err)
+	}
+
+	return os.Rename(filepath.Join(f.dir, filesTrashDir, id+".md"), filepath.Join(f.dir, goalPath(goal.Period, goal.Start)))
+}
+
+func (f *Files) PurgeTrashed(ctx context.Context, id string) error {
+	path := filepath.Join(filesTrashDir, id+".md")
+	if !filepath.IsLocal(path) {
+		return fmt.Errorf("invalid trashed goal id %s", id)
+	}
+
+	return os.Remove(filepath.Join(f.dir, path))
+}
+
+// ReadSettings reads settings.toml with settings as string keys
+func (f *Files) ReadSettings(ctx context.Context) ([]model.Setting, error) {
+	path := filepath.Join(f.dir, filesSettingsFile)
+	values := make(map[string]string)
+	if _, err := toml.DecodeFile(path, &values); errors.Is(err, fs.ErrNotExist) {
+		return make([]model.Setting, 0), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat settings: %w", err)
+	}
+
+	result := make([]model.Setting, 0, len(values))
+	for _, id := range slices.Sorted(maps.Keys(values)) {
+		result = append(result, model.Setting{ID: id, Value: values[id], Updated: info.ModTime()})
+	}
+
+	return result, nil
+}
+
+func (f *Files) UpdateSetting(ctx context.Context, setting model.Setting) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	settings, err := f.ReadSettings(ctx)
+	if err != nil {
+		return err
+	}
+
+	values := map[string]string{setting.ID: setting.Value}
+	for _, existing := range settings {
+		if existing.ID != setting.ID {
+			values[existing.ID] = existing.Value
+		}
+	}
+
+	return f.writeTOML(filesSettingsFile, values)
+}
+
+func (f *Files) writeTOML(name string, value any) error {
+	var out strings.Builder
+	if err := toml.NewEncoder(&out).Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+
+	return writeFileAtomic(filepath.Join(f.dir, name), []byte(out.String()))
+}
+
+type filesRecurring struct {
+	Recurring []filesRecurringItem `toml:"recurring"`
+}
+
+type filesRecurringItem struct {
+	ID       string    `toml:"id"`
+	Schedule string    `toml:"schedule"`
+	Content  string    `toml:"content"`
+	Updated  time.Time `toml:"updated"`
+}
+
+// ReadRecurring reads recurring.toml with [[recurring]] tables
+func (f *Files) ReadRecurring(ctx context.Context) ([]model.Recurring, error) {
+	var file filesRecurring
+	if _, err := toml.DecodeFile(filepath.Join(f.dir, filesRecurringFile), &file); errors.Is(err, fs.ErrNotExist) {
+		return make([]model.Recurring, 0), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read recurring: %w", err)
+	}
+
+	result := make([]model.Recurring, 0, len(file.Recurring))
+	for _, item := range file.Recurring {
+		result = append(result, model.Recurring(item))
+	}
+
+	return result, nil
+}
+
+func (f *Files) writeRecurring(items []model.Recurring) error {
+	file := filesRecurring{Recurring: make([]filesRecurringItem, 0, len(items))}
+	for _, item := range items {
+		file.Recurring = append(file.Recurring, filesRecurringItem(item))
+	}
+
+	return f.writeTOML(filesRecurringFile, file)
+}
+
+func (f *Files) UpdateRecurring(ctx context.Context, recurring model.Recurring) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	items, err := f.ReadRecurring(ctx)
+	if err != nil {
+		return err
+	}
+
+	items = slices.DeleteFunc(items, func(item model.Recurring) bool { return item.ID == recurring.ID })
+	return f.writeRecurring(append(items, recurring))
+}
+
+func (f *Files) DeleteRecurring(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	items, err := f.ReadRecurring(ctx)
+	if err != nil {
+		return err
+	}
+
+	items = slices.DeleteFunc(items, func(item model.Recurring) bool { return item.ID == id })
+	return f.writeRecurring(items)
+}
+
+// Vacuum removes empty goal files, cleared goals are moved to the trash with their last non-empty content
+func (f *Files) Vacuum(ctx context.Context, now time.Time) error {
+	for _, period := range model.Periods {
+		entries, err := os.ReadDir(filepath.Join(f.dir, periodDir(period)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read goals: %w", err)
+		}
+
+		for _, entry := range entries {
+			path := filepath.Join(periodDir(period), entry.Name())
+			if info, err := entry.Info(); err != nil || entry.IsDir() || info.Size() > 0 || !strings.HasSuffix(path, ".md") {
+				continue
+			}
+
+			if err := f.trashPath(path, now); err != nil {
+				return fmt.Errorf("failed to trash cleared goal: %w", err)
+			}
+		}
+	}
+
+	// left from goals filled again outside of the app
+	if err := os.RemoveAll(filepath.Join(f.dir, filesClearedDir)); err != nil {
+		return fmt.Errorf("failed to remove cleared content: %w", err)
+	}
+
+	return nil
+}
+
+func (f *Files) Close() error {
+	return nil
+}
@@ -0,0 +1,224 @@
+package storage
+
+import (
+	"errors"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/utils"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestGoalName(t *testing.T) {
+	type testData struct {
+		period   model.Period
+		start    time.Time
+		expected string
+	}
+
+	inputsExpecteds := []testData{
+		{model.Year, time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local), "2024"},
+		{model.Quarter, time.Date(2024, 10, 1, 0, 0, 0, 0, time.Local), "2024-Q4"},
+		{model.Week, time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local), "2024-W50"},
+		{model.Week, time.Date(2024, 12, 30, 0, 0, 0, 0, time.Local), "2025-W01"},
+		{model.Week, time.Date(2021, 1, 4, 0, 0, 0, 0, time.Local), "2021-W01"},
+		{model.Week, time.Date(2020, 12, 28, 0, 0, 0, 0, time.Local), "2020-W53"},
+		{model.Day, time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local), "2024-12-10"},
+	}
+
+	for _, inputExpected := range inputsExpecteds {
+		t.Run(inputExpected.expected, func(t *testing.T) {
+			if actual := goalName(inputExpected.period, inputExpected.start); actual != inputExpected.expected {
+				t.Errorf("expected %s, got %s", inputExpected.expected, actual)
+			}
+
+			start, err := parseGoalName(inputExpected.period, inputExpected.expected)
+			if err != nil {
+				t.Fatal("unexpected error:", err)
+			}
+
+			if !start.Equal(inputExpected.start) {
+				t.Errorf("expected %v, got %v", inputExpected.start, start)
+			}
+		})
+	}
+}
+
+func TestGoalName_WeekStartsOnSunday(t *testing.T) {
+	utils.SetWeekStart(time.Sunday)
+	defer utils.SetWeekStart(time.Monday)
+
+	sunday := time.Date(2024, 12, 8, 0, 0, 0, 0, time.Local)
+	if name := goalName(model.Week, sunday); name != "2024-W50" {
+		t.Errorf("expected 2024-W50, got %s", name)
+	}
+
+	start, err := parseGoalName(model.Week, "2024-W50")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if !start.Equal(sunday) {
+		t.Errorf("expected %v, got %v", sunday, start)
+	}
+}
+
+func TestFiles_Goals(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	f, err := NewFiles(dir)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	start := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+	goal := model.Goal{ID: "session-id", Period: model.Day, Content: "* write tests", Start: start, Updated: start}
+	if err := f.UpdateGoal(ctx, goal); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "day", "2024-12-10.md"))
+	if err != nil || string(content) != goal.Content {
+		t.Fatalf("expected goal file, got %q %v", content, err)
+	}
+
+	// edited outside
+	if err := os.WriteFile(filepath.Join(dir, "day", "2024-12-09.md"), []byte("* external"), 0600); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	goals, err := f.ReadGoalsForPeriod(ctx, model.Day)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(goals) != 2 || goals[0].ID != "session-id" || goals[1].ID != "day/2024-12-09" || goals[1].Content != "* external" {
+		t.Errorf("unexpected goals %v", goals)
+	}
+
+	// backspaced to nothing, typed again and cleared again
+	for _, content := range []string{"", "* w", ""} {
+		goal.Content = content
+		if err := f.UpdateGoal(ctx, goal); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	if err := f.TrashGoal(ctx, "day/2024-12-09", start); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if amount, err := f.CountGoalsForPeriod(ctx, model.Day); err != nil || amount != 0 {
+		t.Errorf("expected no goals, got %d %v", amount, err)
+	}
+
+	trashed, err := f.ReadTrash(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(trashed) != 1 {
+		t.Fatalf("expected only the deleted goal in trash before vacuum, got %v", trashed)
+	}
+
+	// in the same second as the deletion
+	if err := f.Vacuum(ctx, start); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	trashed, err = f.ReadTrash(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	contents := make([]string, 0, len(trashed))
+	for _, goal := range trashed {
+		contents = append(contents, goal.Content)
+	}
+	slices.Sort(contents)
+
+	if !reflect.DeepEqual(contents, []string{"* external", "* w"}) {
+		t.Fatalf("expected cleared and deleted goals in trash, got %v", trashed)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "day", "2024-12-10.md")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the empty goal file to be removed, got %v", err)
+	}
+
+	// deleted again at the same time, the previous trashed version stays
+	if err := os.WriteFile(filepath.Join(dir, "day", "2024-12-09.md"), []byte("* again"), 0600); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := f.TrashGoal(ctx, "day/2024-12-09", start); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	trashed, err = f.ReadTrash(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(trashed) != 3 {
+		t.Fatalf("expected every trashed version, got %v", trashed)
+	}
+
+	if err := f.RestoreGoal(ctx, trashed[0].ID); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if amount, err := f.CountGoalsForPeriod(ctx, model.Day); err != nil || amount != 1 {
+		t.Errorf("expected restored goal, got %d %v", amount, err)
+	}
+
+	if err := f.PurgeTrashed(ctx, trashed[1].ID); err != nil {
+		t.Error("unexpected error:", err)
+	}
+}
+
+func TestFiles_SettingsRecurring(t *testing.T) {
+	ctx := t.Context()
+
+	f, err := NewFiles(t.TempDir())
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for _, setting := range []model.Setting{{ID: "theme", Value: "light"}, {ID: "theme", Value: "dark"}, {ID: "week_start", Value: "sunday"}} {
+		if err := f.UpdateSetting(ctx, setting); err != nil {
+			t.Fatal("unexpected error:", err)
+		}
+	}
+
+	settings, err := f.ReadSettings(ctx)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if len(settings) != 2 || settings[0].Value != "dark" || settings[1].Value != "sunday" {
+		t.Errorf("unexpected settings %v", settings)
+	}
+
+	recurring := model.Recurring{ID: "id", Schedule: "daily", Content: "* standup", Updated: time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC)}
+	if err := f.UpdateRecurring(ctx, recurring); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	items, err := f.ReadRecurring(ctx)
+	if err != nil || len(items) != 1 || items[0] != recurring {
+		t.Errorf("unexpected recurring %v %v", items, err)
+	}
+
+	if err := f.DeleteRecurring(ctx, "id"); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if items, err := f.ReadRecurring(ctx); err != nil || len(items) != 0 {
+		t.Errorf("expected no recurring, got %v %v", items, err)
+	}
+}
@@ -3,14 +3,28 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"github.com/nvbn/termonizer/internal/model"
 	"github.com/nvbn/termonizer/internal/utils"
+	"strings"
+	"time"
 )
 
+// edits of a goal within the interval are kept as one revision, so autosave on every change doesn't bloat history
+const revisionsCoalesceInterval = 5 * time.Minute
+
+// queryer is either the database or the transaction of a unit of work
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type SQLite struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *contentCipher // set by Unlock when the database is encrypted
 }
 
 func NewSQLite(ctx context.Context, path string) (*SQLite, error) {
@@ -31,6 +45,41 @@ func NewSQLite(ctx context.Context, path string) (*SQLite, error) {
 	return s, nil
 }
 
+// sqliteGoalsTx reads and changes goals in the transaction of a unit of work
+type sqliteGoalsTx struct {
+	s  *SQLite
+	tx *sql.Tx
+}
+
+func (t *sqliteGoalsTx) ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error) {
+	return t.s.readGoalsForPeriod(ctx, t.tx, period)
+}
+
+func (t *sqliteGoalsTx) UpdateGoal(ctx context.Context, goal model.Goal) error {
+	return t.s.updateGoal(ctx, t.tx, goal)
+}
+
+// Tx runs fn as a unit of work, changes made with tx are committed only when fn succeeds, changes made with
+// the storage itself aren't part of the unit of work
+func (s *SQLite) Tx(ctx context.Context, fn func(tx GoalsTx) error) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error { return fn(&sqliteGoalsTx{s: s, tx: tx}) })
+}
+
+// withTx runs fn in a new transaction committed when fn succeeds
+func (s *SQLite) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (s *SQLite) initSchema(ctx context.Context) error {
 	if _, err := s.db.ExecContext(ctx, `
 		create table if not exists Goals (
@@ -43,6 +92,43 @@ func (s *SQLite) initSchema(ctx context.Context) error {
 		return fmt.Errorf("failed to create Goals table: %w", err)
 	}
 
+	// the last non-empty content, so cleared goals end up in the trash instead of being removed
+	added, err := s.addColumnIfMissing(ctx, "Goals", "last_content", `text not null default ""`)
+	if err != nil {
+		return err
+	}
+
+	// goals stored before the column existed would be removed instead of trashed when cleared
+	if added {
+		if _, err := s.db.ExecContext(ctx, `update Goals set last_content = content where content != ""`); err != nil {
+			return fmt.Errorf("failed to fill Goals.last_content: %w", err)
+		}
+	}
+
+	// pinned revisions are shared with other databases by merge, so they're never coalesced
+	if _, err := s.db.ExecContext(ctx, `
+		create table if not exists Revisions (
+		    goal_id text,
+		    content text,
+		    updated timestamp,
+		    pinned integer not null default 0,
+		    primary key (goal_id, updated)
+		)`); err != nil {
+		return fmt.Errorf("failed to create Revisions table: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `
+		create table if not exists Trash (
+		    id text primary key,
+		    period integer,
+		    content text,
+		    start timestamp,
+		    updated timestamp,
+		    deleted timestamp
+		)`); err != nil {
+		return fmt.Errorf("failed to create Trash table: %w", err)
+	}
+
 	if _, err := s.db.ExecContext(ctx, `
 		create table if not exists Settings (
 		    id text primary key,
@@ -52,11 +138,54 @@ func (s *SQLite) initSchema(ctx context.Context) error {
 		return fmt.Errorf("failed to create Settings table: %w", err)
 	}
 
+	if _, err := s.db.ExecContext(ctx, `
+		create table if not exists Recurring (
+		    id text primary key,
+		    schedule text,
+		    content text,
+		    updated timestamp
+		)`); err != nil {
+		return fmt.Errorf("failed to create Recurring table: %w", err)
+	}
+
+	if _, err := s.db.ExecContext(ctx, `
+		create table if not exists Encryption (
+		    id integer primary key check (id = 1),
+		    salt blob,
+		    verifier text
+		)`); err != nil {
+		return fmt.Errorf("failed to create Encryption table: %w", err)
+	}
+
 	return nil
 }
 
+// addColumnIfMissing returns true when the column is added, so existing rows could be migrated
+func (s *SQLite) addColumnIfMissing(ctx context.Context, table string, column string, definition string) (bool, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `
+		select count(*) from pragma_table_info(?) where name = ?
+	`, table, column).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to read %s columns: %w", table, err)
+	}
+
+	if count > 0 {
+		return false, nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("alter table %s add column %s %s", table, column, definition)); err != nil {
+		return false, fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
+
+	return true, nil
+}
+
 func (s *SQLite) ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	return s.readGoalsForPeriod(ctx, s.db, period)
+}
+
+func (s *SQLite) readGoalsForPeriod(ctx context.Context, q queryer, period int) ([]model.Goal, error) {
+	rows, err := q.QueryContext(ctx, `
 		select
 		    id,
 		    period,
@@ -86,6 +215,9 @@ func (s *SQLite) ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Go
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan goals: %w", err)
 		}
+		if goal.Content, err = s.cipher.decrypt(goal.ID, goal.Content); err != nil {
+			return nil, err
+		}
 		goal.Start = utils.IgnoreTZ(goal.Start)
 		result = append(result, goal)
 	}
@@ -93,6 +225,25 @@ func (s *SQLite) ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Go
 	return result, nil
 }
 
+// SearchGoals finds non-empty goals containing the query ignoring case, matched in memory as content could be encrypted
+func (s *SQLite) SearchGoals(ctx context.Context, query string) ([]model.Goal, error) {
+	result := make([]model.Goal, 0)
+	for _, period := range model.Periods {
+		goals, err := s.ReadGoalsForPeriod(ctx, period)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, goal := range goals {
+			if strings.Contains(strings.ToLower(goal.Content), strings.ToLower(query)) {
+				result = append(result, goal)
+			}
+		}
+	}
+
+	return result, nil
+}
+
 func (s *SQLite) CountGoalsForPeriod(ctx context.Context, period int) (int, error) {
 	var count int
 	if err := s.db.QueryRowContext(ctx, `
@@ -109,24 +260,277 @@ func (s *SQLite) CountGoalsForPeriod(ctx context.Context, period int) (int, erro
 	return count, nil
 }
 
-func (s *SQLite) UpdateGoal(ctx context.Context, goals model.Goal) error {
-	_, err := s.db.ExecContext(
+func (s *SQLite) UpdateGoal(ctx context.Context, goal model.Goal) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error { return s.updateGoal(ctx, tx, goal) })
+}
+
+func (s *SQLite) updateGoal(ctx context.Context, tx *sql.Tx, goals model.Goal) error {
+	content, err := s.cipher.encrypt(goals.ID, goals.Content)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addRevision(ctx, tx, goals, content); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(
 		ctx,
 		`
-			insert or replace into Goals (
+			insert into Goals (
 				id,
 				period,
 				content,
 				start,
-				updated
-			) values (?, ?, ?, ?, ?)
+				updated,
+				last_content
+			) values (?, ?, ?, ?, ?, ?)
+			on conflict (id) do update set
+				period = excluded.period,
+				content = excluded.content,
+				start = excluded.start,
+				updated = excluded.updated,
+				last_content = case
+					when excluded.content != "" then excluded.content
+					else Goals.last_content
+				end
 		`,
 		goals.ID,
 		goals.Period,
-		goals.Content,
+		content,
 		goals.Start,
 		goals.Updated,
+		content,
 	)
+	if err != nil {
+		return fmt.Errorf("failed to update goal: %w", err)
+	}
+
+	return nil
+}
+
+// addRevision saves the new content to the history, a burst of edits is coalesced into one revision
+func (s *SQLite) addRevision(ctx context.Context, tx *sql.Tx, goal model.Goal, content string) error {
+	var latestContent string
+	var latestUpdated time.Time
+	var pinned bool
+	err := tx.QueryRowContext(ctx, `
+		select content, updated, pinned
+		from Revisions
+		where goal_id = ?
+		order by updated desc
+		limit 1
+	`, goal.ID).Scan(&latestContent, &latestUpdated, &pinned)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to read revision: %w", err)
+	}
+
+	found := err == nil
+	if found {
+		plaintext, err := s.cipher.decrypt(goal.ID, latestContent)
+		if err != nil {
+			return err
+		}
+
+		if plaintext == goal.Content {
+			return nil
+		}
+	}
+
+	if found && !pinned && goal.Updated.Sub(latestUpdated) < revisionsCoalesceInterval {
+		_, err = tx.ExecContext(ctx, `
+			update Revisions
+			set content = ?, updated = ?
+			where goal_id = ? and updated = ?
+		`, content, goal.Updated, goal.ID, latestUpdated)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			insert or ignore into Revisions (goal_id, content, updated) values (?, ?, ?)
+		`, goal.ID, content, goal.Updated)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	return nil
+}
+
+// ReadRevisions returns the history of the goal from the oldest
+func (s *SQLite) ReadRevisions(ctx context.Context, goalID string) ([]model.Revision, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select goal_id, content, updated
+		from Revisions
+		where goal_id = ?
+		order by updated
+	`, goalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Revision, 0)
+	for rows.Next() {
+		revision := model.Revision{}
+		if err := rows.Scan(&revision.GoalID, &revision.Content, &revision.Updated); err != nil {
+			return nil, fmt.Errorf("failed to scan revisions: %w", err)
+		}
+		if revision.Content, err = s.cipher.decrypt(revision.GoalID, revision.Content); err != nil {
+			return nil, err
+		}
+		result = append(result, revision)
+	}
+
+	return result, nil
+}
+
+// ImportRevisions adds revisions from another database, they're pinned so both databases keep them as is
+func (s *SQLite) ImportRevisions(ctx context.Context, revisions []model.Revision) error {
+	for _, revision := range revisions {
+		content, err := s.cipher.encrypt(revision.GoalID, revision.Content)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.db.ExecContext(ctx, `
+			insert into Revisions (goal_id, content, updated, pinned) values (?, ?, ?, 1)
+			on conflict (goal_id, updated) do update set pinned = 1
+		`, revision.GoalID, content, revision.Updated); err != nil {
+			return fmt.Errorf("failed to import revision: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReadAllGoals returns goals of all periods including empty ones
+func (s *SQLite) ReadAllGoals(ctx context.Context) ([]model.Goal, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select
+		    id,
+		    period,
+		    content,
+		    start,
+		    updated
+		from Goals
+		order by period, start desc
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.Goal, 0)
+	for rows.Next() {
+		goal := model.Goal{}
+		if err := rows.Scan(
+			&goal.ID,
+			&goal.Period,
+			&goal.Content,
+			&goal.Start,
+			&goal.Updated,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan goals: %w", err)
+		}
+		if goal.Content, err = s.cipher.decrypt(goal.ID, goal.Content); err != nil {
+			return nil, err
+		}
+		goal.Start = utils.IgnoreTZ(goal.Start)
+		result = append(result, goal)
+	}
+
+	return result, nil
+}
+
+// TrashGoal moves the goal to the trash, cleared goals are trashed with their last non-empty content
+func (s *SQLite) TrashGoal(ctx context.Context, id string, deleted time.Time) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			insert or replace into Trash (id, period, content, start, updated, deleted)
+			select
+			    id,
+			    period,
+			    case when content != "" then content else last_content end,
+			    start,
+			    updated,
+			    ?
+			from Goals
+			where
+			    id = ?
+			    and (content != "" or last_content != "")
+		`, deleted, id); err != nil {
+			return fmt.Errorf("failed to trash goal: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `delete from Goals where id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete goal: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) ReadTrash(ctx context.Context) ([]model.TrashedGoal, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		select
+		    id,
+		    period,
+		    content,
+		    start,
+		    updated,
+		    deleted
+		from Trash
+		order by deleted desc
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trash: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]model.TrashedGoal, 0)
+	for rows.Next() {
+		goal := model.TrashedGoal{}
+		if err := rows.Scan(
+			&goal.ID,
+			&goal.Period,
+			&goal.Content,
+			&goal.Start,
+			&goal.Updated,
+			&goal.Deleted,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan trash: %w", err)
+		}
+		if goal.Content, err = s.cipher.decrypt(goal.ID, goal.Content); err != nil {
+			return nil, err
+		}
+		goal.Start = utils.IgnoreTZ(goal.Start)
+		result = append(result, goal)
+	}
+
+	return result, nil
+}
+
+// RestoreGoal moves the goal from the trash back to goals
+func (s *SQLite) RestoreGoal(ctx context.Context, id string) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			insert or replace into Goals (id, period, content, start, updated, last_content)
+			select id, period, content, start, updated, content
+			from Trash
+			where id = ?
+		`, id); err != nil {
+			return fmt.Errorf("failed to restore goal: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `delete from Trash where id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete from trash: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) PurgeTrashed(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `delete from Trash where id = ?`, id)
 	return err
 }
 
@@ -158,16 +562,132 @@ func (s *SQLite) UpdateSetting(ctx context.Context, settings model.Setting) erro
 	return err
 }
 
-// Vacuum removes old empty goals
-func (s *SQLite) Vacuum(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, `
-		delete from Goals
-		where content = ""
-	`)
+func (s *SQLite) ReadRecurring(ctx context.Context) ([]model.Recurring, error) {
+	rows, err := s.db.QueryContext(ctx, `select id, schedule, content, updated from Recurring order by updated`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurring: %w", err)
+	}
+	defer rows.Close()
 
+	result := make([]model.Recurring, 0)
+	for rows.Next() {
+		recurring := model.Recurring{}
+		if err := rows.Scan(&recurring.ID, &recurring.Schedule, &recurring.Content, &recurring.Updated); err != nil {
+			return nil, fmt.Errorf("failed to scan recurring: %w", err)
+		}
+		if recurring.Content, err = s.cipher.decrypt(recurring.ID, recurring.Content); err != nil {
+			return nil, err
+		}
+		result = append(result, recurring)
+	}
+
+	return result, nil
+}
+
+func (s *SQLite) UpdateRecurring(ctx context.Context, recurring model.Recurring) error {
+	content, err := s.cipher.encrypt(recurring.ID, recurring.Content)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(
+		ctx,
+		`insert or replace into Recurring (id, schedule, content, updated) values (?, ?, ?, ?)`,
+		recurring.ID, recurring.Schedule, content, recurring.Updated,
+	)
 	return err
 }
 
+func (s *SQLite) DeleteRecurring(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, `delete from Recurring where id = ?`, id)
+	return err
+}
+
+// Vacuum removes goals that were never non-empty, cleared goals are moved to the trash
+func (s *SQLite) Vacuum(ctx context.Context, now time.Time) error {
+	return s.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+			insert or replace into Trash (id, period, content, start, updated, deleted)
+			select id, period, last_content, start, updated, ?
+			from Goals
+			where
+			    content = ""
+			    and last_content != ""
+		`, now); err != nil {
+			return fmt.Errorf("failed to trash cleared goals: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			delete from Goals
+			where content = ""
+		`); err != nil {
+			return fmt.Errorf("failed to delete empty goals: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			delete from Revisions
+			where goal_id not in (select id from Goals union select id from Trash)
+		`); err != nil {
+			return fmt.Errorf("failed to delete revisions of removed goals: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Backup writes a consistent snapshot of the database to the path with the online backup API
+func (s *SQLite) Backup(ctx context.Context, path string) error {
+	dst, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer dst.Close()
+
+	return copyDatabase(ctx, dst, s.db)
+}
+
+// Restore replaces the content of the database with the snapshot
+func (s *SQLite) Restore(ctx context.Context, path string) error {
+	// read only, so a missing snapshot isn't created empty
+	src, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer src.Close()
+
+	return copyDatabase(ctx, s.db, src)
+}
+
+func copyDatabase(ctx context.Context, dst *sql.DB, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to destination: %w", err)
+	}
+	defer dstConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to source: %w", err)
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstDriverConn any) error {
+		return srcConn.Raw(func(srcDriverConn any) error {
+			backup, err := dstDriverConn.(*sqlite3.SQLiteConn).Backup("main", srcDriverConn.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return fmt.Errorf("failed to start backup: %w", err)
+			}
+
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return fmt.Errorf("failed to copy database: %w", err)
+			}
+
+			return backup.Finish()
+		})
+	})
+}
+
 func (s *SQLite) Close() error {
 	return s.db.Close()
 }
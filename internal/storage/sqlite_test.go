@@ -1,10 +1,14 @@
 package storage
 
 import (
+	"database/sql"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/nvbn/termonizer/internal/model"
 	"github.com/nvbn/termonizer/internal/utils"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 )
@@ -73,6 +77,69 @@ func TestSQLite_Goals(t *testing.T) {
 	}
 }
 
+func TestSQLite_Tx(t *testing.T) {
+	ctx := t.Context()
+
+	// a file as every connection to an in-memory database has its own one
+	s, err := NewSQLite(ctx, filepath.Join(t.TempDir(), "goals.db"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.Close()
+
+	date := utils.IgnoreTZ(time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC))
+	week := model.Goal{ID: uuid.New().String(), Period: model.Week, Content: "* plan", Start: date, Updated: date}
+	day := model.Goal{ID: uuid.New().String(), Period: model.Day, Content: "* [ ] moved", Start: date, Updated: date}
+
+	failed := errors.New("failed")
+	err = s.Tx(ctx, func(tx GoalsTx) error {
+		if err := tx.UpdateGoal(ctx, week); err != nil {
+			return err
+		}
+
+		// changes are visible inside the unit of work
+		if goals, err := tx.ReadGoalsForPeriod(ctx, model.Week); err != nil || len(goals) != 1 {
+			t.Errorf("expected the goal, got %v: %v", goals, err)
+		}
+
+		// and only there until it's committed
+		if goals, err := s.ReadGoalsForPeriod(ctx, model.Week); err != nil || len(goals) != 0 {
+			t.Errorf("expected the goal to be visible only in the unit of work, got %v: %v", goals, err)
+		}
+
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Errorf("expected %v, got %v", failed, err)
+	}
+
+	if goals, err := s.ReadGoalsForPeriod(ctx, model.Week); err != nil || len(goals) != 0 {
+		t.Errorf("expected the change to be rolled back, got %v: %v", goals, err)
+	}
+
+	err = s.Tx(ctx, func(tx GoalsTx) error {
+		if err := tx.UpdateGoal(ctx, week); err != nil {
+			return err
+		}
+
+		return tx.UpdateGoal(ctx, day)
+	})
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	for _, expected := range []model.Goal{week, day} {
+		goals, err := s.ReadGoalsForPeriod(ctx, expected.Period)
+		if err != nil {
+			t.Error("unexpected error:", err)
+		}
+
+		if len(goals) != 1 || goals[0].Content != expected.Content {
+			t.Errorf("expected %v, got %v", expected, goals)
+		}
+	}
+}
+
 func TestSQLite_Settings(t *testing.T) {
 	ctx := t.Context()
 
@@ -111,3 +178,269 @@ func TestSQLite_Settings(t *testing.T) {
 		t.Errorf("expected %v, got %v", expected, settings)
 	}
 }
+
+func TestSQLite_Recurring(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := NewSQLite(ctx, ":memory:")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+	defer s.Close()
+
+	recurring := model.Recurring{
+		ID:       uuid.New().String(),
+		Schedule: "monday",
+		Content:  "* weekly 1:1 prep",
+		Updated:  time.Now().UTC(),
+	}
+
+	if err := s.UpdateRecurring(ctx, recurring); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	items, err := s.ReadRecurring(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	expected := []model.Recurring{recurring}
+	if !reflect.DeepEqual(expected, items) {
+		t.Errorf("expected %v, got %v", expected, items)
+	}
+
+	if err := s.DeleteRecurring(ctx, recurring.ID); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	items, err = s.ReadRecurring(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(items) != 0 {
+		t.Errorf("expected 0 items, got %d", len(items))
+	}
+}
+
+func TestSQLite_Trash(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := NewSQLite(ctx, ":memory:")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+	defer s.Close()
+
+	date := time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC)
+	deleted := model.Goal{ID: uuid.New().String(), Period: 0, Content: "deleted", Start: date, Updated: date}
+	cleared := model.Goal{ID: uuid.New().String(), Period: 0, Content: "cleared", Start: date, Updated: date}
+	neverFilled := model.Goal{ID: uuid.New().String(), Period: 0, Content: "", Start: date, Updated: date}
+
+	for _, goal := range []model.Goal{deleted, cleared, neverFilled} {
+		if err := s.UpdateGoal(ctx, goal); err != nil {
+			t.Error("unexpected error:", err)
+		}
+	}
+
+	cleared.Content = ""
+	if err := s.UpdateGoal(ctx, cleared); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if err := s.TrashGoal(ctx, deleted.ID, date); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if err := s.Vacuum(ctx, date); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	trashed, err := s.ReadTrash(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	contents := make([]string, 0)
+	for _, goal := range trashed {
+		contents = append(contents, goal.Content)
+	}
+	slices.Sort(contents)
+
+	if !reflect.DeepEqual(contents, []string{"cleared", "deleted"}) {
+		t.Errorf("expected cleared and deleted goals in trash, got %v", contents)
+	}
+
+	if err := s.RestoreGoal(ctx, deleted.ID); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	goals, err := s.ReadGoalsForPeriod(ctx, 0)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(goals) != 1 || goals[0].Content != "deleted" {
+		t.Errorf("expected restored goal, got %v", goals)
+	}
+
+	if err := s.PurgeTrashed(ctx, cleared.ID); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	trashed, err = s.ReadTrash(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(trashed) != 0 {
+		t.Errorf("expected empty trash, got %v", trashed)
+	}
+}
+
+func TestSQLite_MigrateLastContent(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "goals.db")
+	date := time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC)
+
+	// the schema before last_content
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		create table Goals (id text primary key, period integer, content text, start timestamp, updated timestamp);
+		insert into Goals values ('filled', 0, 'before upgrade', ?, ?), ('empty', 0, '', ?, ?);
+	`, date, date, date, date); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	s, err := NewSQLite(ctx, path)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.Close()
+
+	if err := s.UpdateGoal(ctx, model.Goal{ID: "filled", Period: 0, Content: "", Start: date, Updated: date}); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if err := s.Vacuum(ctx, date); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	trashed, err := s.ReadTrash(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(trashed) != 1 || trashed[0].ID != "filled" || trashed[0].Content != "before upgrade" {
+		t.Errorf("expected the goal cleared after the upgrade in trash, got %v", trashed)
+	}
+}
+
+func TestSQLite_BackupRestore(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+
+	s, err := NewSQLite(ctx, filepath.Join(dir, "termonizer.db"))
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+	defer s.Close()
+
+	setting := model.Setting{ID: "theme", Value: "light", Updated: time.Now().UTC()}
+	if err := s.UpdateSetting(ctx, setting); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	snapshot := filepath.Join(dir, "snapshot.db")
+	if err := s.Backup(ctx, snapshot); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	setting.Value = "dark"
+	if err := s.UpdateSetting(ctx, setting); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if err := s.Restore(ctx, snapshot); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	settings, err := s.ReadSettings(ctx)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(settings) != 1 || settings[0].Value != "light" {
+		t.Errorf("expected restored setting, got %v", settings)
+	}
+
+	if err := s.Restore(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("expected error for missing snapshot")
+	}
+}
+
+func TestSQLite_Revisions(t *testing.T) {
+	ctx := t.Context()
+
+	s, err := NewSQLite(ctx, ":memory:")
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+	defer s.Close()
+
+	date := time.Date(2024, 12, 9, 0, 0, 0, 0, time.UTC)
+	goal := model.Goal{ID: uuid.New().String(), Period: 0, Start: date}
+
+	// edits within a few minutes are a single revision
+	for n, content := range []string{"f", "fi", "first"} {
+		goal.Content, goal.Updated = content, date.Add(time.Duration(n)*time.Minute)
+		if err := s.UpdateGoal(ctx, goal); err != nil {
+			t.Error("unexpected error:", err)
+		}
+	}
+
+	goal.Content, goal.Updated = "second", date.Add(time.Hour)
+	if err := s.UpdateGoal(ctx, goal); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	revisions, err := s.ReadRevisions(ctx, goal.ID)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	expected := []model.Revision{
+		{GoalID: goal.ID, Content: "first", Updated: date.Add(2 * time.Minute)},
+		{GoalID: goal.ID, Content: "second", Updated: date.Add(time.Hour)},
+	}
+	if !reflect.DeepEqual(expected, revisions) {
+		t.Errorf("expected %v, got %v", expected, revisions)
+	}
+
+	// imported revisions are pinned and aren't coalesced with later edits
+	if err := s.ImportRevisions(ctx, revisions[1:]); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	goal.Content, goal.Updated = "third", date.Add(time.Hour+time.Minute)
+	if err := s.UpdateGoal(ctx, goal); err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	revisions, err = s.ReadRevisions(ctx, goal.ID)
+	if err != nil {
+		t.Error("unexpected error:", err)
+	}
+
+	if len(revisions) != 3 {
+		t.Errorf("expected 3 revisions, got %v", revisions)
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/model"
+	"strings"
+	"time"
+)
+
+const filesPrefix = "dir://"
+
+// GoalsTx reads and changes goals in a unit of work started by Storage.Tx
+type GoalsTx interface {
+	ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error)
+	UpdateGoal(ctx context.Context, goal model.Goal) error
+}
+
+// Storage is implemented by every backend
+type Storage interface {
+	ReadGoalsForPeriod(ctx context.Context, period int) ([]model.Goal, error)
+	CountGoalsForPeriod(ctx context.Context, period int) (int, error)
+	UpdateGoal(ctx context.Context, goals model.Goal) error
+	Tx(ctx context.Context, fn func(tx GoalsTx) error) error
+	TrashGoal(ctx context.Context, id string, deleted time.Time) error
+	SearchGoals(ctx context.Context, query string) ([]model.Goal, error)
+	ReadRevisions(ctx context.Context, goalID string) ([]model.Revision, error)
+	ReadTrash(ctx context.Context) ([]model.TrashedGoal, error)
+	RestoreGoal(ctx context.Context, id string) error
+	PurgeTrashed(ctx context.Context, id string) error
+	ReadSettings(ctx context.Context) ([]model.Setting, error)
+	UpdateSetting(ctx context.Context, settings model.Setting) error
+	ReadRecurring(ctx context.Context) ([]model.Recurring, error)
+	UpdateRecurring(ctx context.Context, recurring model.Recurring) error
+	DeleteRecurring(ctx context.Context, id string) error
+	Vacuum(ctx context.Context, now time.Time) error
+	Close() error
+}
+
+var _ Storage = (*SQLite)(nil)
+var _ Storage = (*Files)(nil)
+
+// Open returns Files for dir:///path and SQLite for anything else
+func Open(ctx context.Context, db string) (Storage, error) {
+	if dir, ok := strings.CutPrefix(db, filesPrefix); ok {
+		return NewFiles(dir)
+	}
+
+	return NewSQLite(ctx, db)
+}
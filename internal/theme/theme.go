@@ -0,0 +1,229 @@
+package theme
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"maps"
+	"slices"
+	"strings"
+)
+
+const Default = "dark"
+
+const baseKey = "base"
+
+type Theme struct {
+	Name string
+
+	Background    tcell.Color
+	Text          tcell.Color
+	Border        tcell.Color
+	FocusedBorder tcell.Color
+	PanelTitle    tcell.Color
+	Button        tcell.Color
+	ButtonText    tcell.Color
+	Selection     tcell.Color
+	Placeholder   tcell.Color
+
+	PastTitle   tcell.Color
+	PastText    tcell.Color
+	NowTitle    tcell.Color
+	NowText     tcell.Color
+	FutureTitle tcell.Color
+	FutureText  tcell.Color
+
+	// Added and Removed highlight lines when goals are compared
+	Added   tcell.Color
+	Removed tcell.Color
+}
+
+var builtin = map[string]Theme{
+	"dark": {
+		Name:          "dark",
+		Background:    tcell.ColorBlack,
+		Text:          tcell.ColorWhite,
+		Border:        tcell.ColorWhite,
+		FocusedBorder: tcell.ColorYellow,
+		PanelTitle:    tcell.ColorWhite,
+		Button:        tcell.ColorBlue,
+		ButtonText:    tcell.ColorWhite,
+		Selection:     tcell.ColorBlue,
+		Placeholder:   tcell.ColorDarkGray,
+		PastTitle:     tcell.ColorGray,
+		PastText:      tcell.ColorSilver,
+		NowTitle:      tcell.ColorGreen,
+		NowText:       tcell.ColorWhite,
+		FutureTitle:   tcell.ColorBlue,
+		FutureText:    tcell.ColorWhite,
+		Added:         tcell.ColorGreen,
+		Removed:       tcell.ColorRed,
+	},
+	"light": {
+		Name:          "light",
+		Background:    tcell.ColorWhite,
+		Text:          tcell.ColorBlack,
+		Border:        tcell.ColorGray,
+		FocusedBorder: tcell.ColorNavy,
+		PanelTitle:    tcell.ColorBlack,
+		Button:        tcell.ColorLightSteelBlue,
+		ButtonText:    tcell.ColorBlack,
+		Selection:     tcell.ColorLightBlue,
+		Placeholder:   tcell.ColorDarkGray,
+		PastTitle:     tcell.ColorGray,
+		PastText:      tcell.ColorDimGray,
+		NowTitle:      tcell.ColorDarkGreen,
+		NowText:       tcell.ColorBlack,
+		FutureTitle:   tcell.ColorNavy,
+		FutureText:    tcell.ColorBlack,
+		Added:         tcell.ColorDarkGreen,
+		Removed:       tcell.ColorDarkRed,
+	},
+	"high-contrast": {
+		Name:          "high-contrast",
+		Background:    tcell.ColorBlack,
+		Text:          tcell.ColorWhite,
+		Border:        tcell.ColorWhite,
+		FocusedBorder: tcell.ColorYellow,
+		PanelTitle:    tcell.ColorYellow,
+		Button:        tcell.ColorYellow,
+		ButtonText:    tcell.ColorBlack,
+		Selection:     tcell.ColorFuchsia,
+		Placeholder:   tcell.ColorSilver,
+		PastTitle:     tcell.ColorWhite,
+		PastText:      tcell.ColorWhite,
+		NowTitle:      tcell.ColorLime,
+		NowText:       tcell.ColorWhite,
+		FutureTitle:   tcell.ColorAqua,
+		FutureText:    tcell.ColorWhite,
+		Added:         tcell.ColorLime,
+		Removed:       tcell.ColorRed,
+	},
+	"solarized": {
+		Name:          "solarized",
+		Background:    tcell.NewHexColor(0x002b36),
+		Text:          tcell.NewHexColor(0x839496),
+		Border:        tcell.NewHexColor(0x586e75),
+		FocusedBorder: tcell.NewHexColor(0xb58900),
+		PanelTitle:    tcell.NewHexColor(0x93a1a1),
+		Button:        tcell.NewHexColor(0x073642),
+		ButtonText:    tcell.NewHexColor(0x93a1a1),
+		Selection:     tcell.NewHexColor(0x073642),
+		Placeholder:   tcell.NewHexColor(0x586e75),
+		PastTitle:     tcell.NewHexColor(0x657b83),
+		PastText:      tcell.NewHexColor(0x657b83),
+		NowTitle:      tcell.NewHexColor(0x859900),
+		NowText:       tcell.NewHexColor(0x93a1a1),
+		FutureTitle:   tcell.NewHexColor(0x268bd2),
+		FutureText:    tcell.NewHexColor(0x839496),
+		Added:         tcell.NewHexColor(0x859900),
+		Removed:       tcell.NewHexColor(0xdc322f),
+	},
+}
+
+func (t *Theme) colors() map[string]*tcell.Color {
+	return map[string]*tcell.Color{
+		"background":     &t.Background,
+		"text":           &t.Text,
+		"border":         &t.Border,
+		"focused_border": &t.FocusedBorder,
+		"panel_title":    &t.PanelTitle,
+		"button":         &t.Button,
+		"button_text":    &t.ButtonText,
+		"selection":      &t.Selection,
+		"placeholder":    &t.Placeholder,
+		"past_title":     &t.PastTitle,
+		"past_text":      &t.PastText,
+		"now_title":      &t.NowTitle,
+		"now_text":       &t.NowText,
+		"future_title":   &t.FutureTitle,
+		"future_text":    &t.FutureText,
+		"added":          &t.Added,
+		"removed":        &t.Removed,
+	}
+}
+
+// Themes holds built-in themes and themes defined by the user
+type Themes struct {
+	themes map[string]Theme
+}
+
+func NewThemes() *Themes {
+	return &Themes{themes: maps.Clone(builtin)}
+}
+
+// Define adds user themes described as color names by key, themes could be based on other themes:
+//
+//	{"mine": {"base": "solarized", "future_title": "#ff00ff"}}
+func (t *Themes) Define(themes map[string]map[string]string) error {
+	for _, name := range slices.Sorted(maps.Keys(themes)) {
+		if err := t.define(themes, name, make(map[string]bool)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Themes) define(themes map[string]map[string]string, name string, visiting map[string]bool) error {
+	values := themes[name]
+
+	baseName := Default
+	if value, ok := values[baseKey]; ok {
+		baseName = value
+	}
+
+	// user themes could be based on other user themes
+	if _, ok := themes[baseName]; ok && baseName != name {
+		if visiting[name] {
+			return fmt.Errorf("theme %s: circular base theme %s", name, baseName)
+		}
+		visiting[name] = true
+
+		if err := t.define(themes, baseName, visiting); err != nil {
+			return err
+		}
+	}
+
+	base, ok := t.themes[baseName]
+	if !ok {
+		return fmt.Errorf("theme %s: unknown base theme %s", name, baseName)
+	}
+
+	theme := base
+	theme.Name = name
+	colors := theme.colors()
+	for key, value := range values {
+		if key == baseKey {
+			continue
+		}
+
+		color, ok := colors[key]
+		if !ok {
+			return fmt.Errorf("theme %s: unknown key %s", name, key)
+		}
+
+		parsed := tcell.GetColor(strings.ToLower(value))
+		if parsed == tcell.ColorDefault && value != "default" {
+			return fmt.Errorf("theme %s: invalid color %s for %s", name, value, key)
+		}
+
+		*color = parsed
+	}
+
+	t.themes[name] = theme
+
+	return nil
+}
+
+func (t *Themes) Get(name string) (Theme, error) {
+	theme, ok := t.themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %s, available: %s", name, strings.Join(t.Names(), ", "))
+	}
+
+	return theme, nil
+}
+
+func (t *Themes) Names() []string {
+	return slices.Sorted(maps.Keys(t.themes))
+}
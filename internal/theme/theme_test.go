@@ -0,0 +1,71 @@
+package theme
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+func TestThemes_Get(t *testing.T) {
+	themes := NewThemes()
+
+	for _, name := range []string{"dark", "light", "high-contrast", "solarized"} {
+		t.Run(name, func(t *testing.T) {
+			theme, err := themes.Get(name)
+			if err != nil {
+				t.Error("unexpected error:", err)
+			}
+
+			if theme.Name != name {
+				t.Errorf("expected theme %q, got %q", name, theme.Name)
+			}
+		})
+	}
+
+	if _, err := themes.Get("unknown"); err == nil {
+		t.Error("expected error for unknown theme")
+	}
+}
+
+func TestThemes_Define(t *testing.T) {
+	themes := NewThemes()
+	if err := themes.Define(map[string]map[string]string{
+		"mine":   {"base": "parent", "future_title": "#ff00ff"},
+		"parent": {"base": "light", "now_title": "red"},
+	}); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	mine, err := themes.Get("mine")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if mine.FutureTitle != tcell.NewHexColor(0xff00ff) {
+		t.Errorf("expected future title color from the theme, got %v", mine.FutureTitle)
+	}
+
+	if mine.NowTitle != tcell.ColorRed {
+		t.Errorf("expected now title color from the parent theme, got %v", mine.NowTitle)
+	}
+
+	if mine.Background != builtin["light"].Background {
+		t.Errorf("expected background from the light theme, got %v", mine.Background)
+	}
+}
+
+func TestThemes_Define_Invalid(t *testing.T) {
+	invalid := map[string]map[string]map[string]string{
+		"unknown key":   {"mine": {"unknown": "red"}},
+		"unknown color": {"mine": {"text": "not-a-color"}},
+		"unknown base":  {"mine": {"base": "unknown"}},
+		"circular base": {"a": {"base": "b"}, "b": {"base": "a"}},
+	}
+
+	for name, themes := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := NewThemes().Define(themes); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
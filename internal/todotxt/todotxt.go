@@ -0,0 +1,217 @@
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/nvbn/termonizer/internal/model"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat = "2006-01-02"
+	goalKey    = "goal:"
+	dueKey     = "due:"
+)
+
+var (
+	date           = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	markdownHeader = regexp.MustCompile(`^#+\s+(.*?)\s*$`)
+	whitespace     = regexp.MustCompile(`\s+`)
+)
+
+// project is the closest markdown heading above the item, e.g. "# Team sync" is +team-sync
+func project(title string) string {
+	return strings.ToLower(whitespace.ReplaceAllString(strings.TrimSpace(title), "-"))
+}
+
+// Write exports checklist items one per line, "x" with the last update date for done items, the start of the
+// period as the creation date, the heading as +project, the period as @context and goal:id to import them back
+func Write(out io.Writer, goals []model.Goal) error {
+	w := bufio.NewWriter(out)
+
+	for _, goal := range goals {
+		items := goal.Checklist()
+		if len(items) == 0 {
+			continue
+		}
+
+		lines := strings.Split(goal.Content, "\n")
+		context := "@" + strings.ToLower(model.PeriodName(goal.Period))
+		due := dueKey + goal.End().AddDate(0, 0, -1).Format(dateFormat)
+
+		for _, item := range items {
+			parts := make([]string, 0)
+			if item.Done {
+				parts = append(parts, "x", goal.Updated.Format(dateFormat))
+			}
+
+			parts = append(parts, goal.Start.Format(dateFormat), item.Text)
+
+			for n := item.Line - 1; n >= 0; n-- {
+				if match := markdownHeader.FindStringSubmatch(lines[n]); match != nil {
+					parts = append(parts, "+"+project(match[1]))
+					break
+				}
+			}
+
+			parts = append(parts, context, due, goalKey+goal.ID)
+			fmt.Fprintln(w, strings.Join(parts, " "))
+		}
+	}
+
+	return w.Flush()
+}
+
+type task struct {
+	done    bool
+	created string
+	text    string
+	project string
+	period  model.Period
+	goalID  string
+}
+
+// parseTask reads the format described in https://github.com/todotxt/todo.txt, unknown tags are kept in the text
+func parseTask(line string, now time.Time) task {
+	fields := strings.Fields(line)
+	t := task{period: model.Day, created: now.Format(dateFormat)}
+
+	if len(fields) > 0 && fields[0] == "x" {
+		t.done = true
+		fields = fields[1:]
+		// the completion date
+		if len(fields) > 1 && date.MatchString(fields[0]) && date.MatchString(fields[1]) {
+			fields = fields[1:]
+		}
+	}
+
+	priority := ""
+	if len(fields) > 0 && len(fields[0]) == 3 && fields[0][0] == '(' && fields[0][2] == ')' {
+		priority, fields = fields[0], fields[1:]
+	}
+
+	if len(fields) > 0 && date.MatchString(fields[0]) {
+		t.created, fields = fields[0], fields[1:]
+	}
+
+	text := make([]string, 0, len(fields))
+	if priority != "" {
+		text = append(text, priority)
+	}
+
+	for _, field := range fields {
+		if after, ok := strings.CutPrefix(field, goalKey); ok {
+			t.goalID = after
+		} else if strings.HasPrefix(field, dueKey) {
+			// due is derived from the period
+			continue
+		} else if after, ok := strings.CutPrefix(field, "@"); ok && t.period == model.Day && after != "" {
+			if period, err := model.ParsePeriod(after); err == nil {
+				t.period = period
+			} else {
+				text = append(text, field)
+			}
+		} else if after, ok := strings.CutPrefix(field, "+"); ok && t.project == "" && after != "" {
+			t.project = after
+		} else {
+			text = append(text, field)
+		}
+	}
+
+	t.text = strings.Join(text, " ")
+
+	return t
+}
+
+// Read imports tasks as checklist items of goals grouped by goal:id or by the period and the creation date,
+// items of the same +project are grouped under its heading, tasks without dates are added to today
+func Read(in io.Reader, now time.Time) ([]model.Goal, error) {
+	tasks := make([]task, 0)
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		tasks = append(tasks, parseTask(scanner.Text(), now))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read: %w", err)
+	}
+
+	type group struct {
+		goal     model.Goal
+		projects []string
+		items    map[string][]string
+	}
+
+	groups := make([]*group, 0)
+	keyToGroup := make(map[string]*group)
+	for _, t := range tasks {
+		start, err := time.ParseInLocation(dateFormat, t.created, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date in %q: %w", t.text, err)
+		}
+
+		goal := model.NewGoal(t.period, start)
+		key := t.goalID
+		if key == "" {
+			key = fmt.Sprintf("%d %s", goal.Period, goal.FormatStart())
+		}
+
+		g, ok := keyToGroup[key]
+		if !ok {
+			goal.ID = t.goalID
+			if goal.ID == "" {
+				goal.ID = uuid.New().String()
+			}
+
+			g = &group{goal: goal, items: make(map[string][]string)}
+			keyToGroup[key] = g
+			groups = append(groups, g)
+		}
+
+		if !slices.Contains(g.projects, t.project) {
+			g.projects = append(g.projects, t.project)
+		}
+
+		box := "[ ]"
+		if t.done {
+			box = "[x]"
+		}
+		g.items[t.project] = append(g.items[t.project], fmt.Sprintf("* %s %s", box, t.text))
+	}
+
+	goals := make([]model.Goal, 0, len(groups))
+	for _, g := range groups {
+		// items without a project go first, so they aren't under a heading
+		slices.SortStableFunc(g.projects, func(a, b string) int {
+			if a == "" && b != "" {
+				return -1
+			} else if b == "" && a != "" {
+				return 1
+			}
+			return 0
+		})
+
+		lines := make([]string, 0)
+		for _, name := range g.projects {
+			if name != "" {
+				lines = append(lines, "# "+name)
+			}
+			lines = append(lines, g.items[name]...)
+		}
+
+		g.goal.Content = strings.Join(lines, "\n")
+		goals = append(goals, g.goal)
+	}
+
+	return goals, nil
+}
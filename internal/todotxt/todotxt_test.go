@@ -0,0 +1,75 @@
+package todotxt
+
+import (
+	"bytes"
+	"github.com/nvbn/termonizer/internal/model"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	start := time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)
+	updated := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+	goals := []model.Goal{
+		{ID: "week", Period: model.Week, Start: start, Updated: updated, Content: "* [ ] write report\n# work\n* [x] call Bob\n* [ ] plan q1"},
+		{ID: "day", Period: model.Day, Start: start, Updated: updated, Content: "* [ ] (A) pay rent"},
+	}
+
+	var out bytes.Buffer
+	if err := Write(&out, goals); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	expected := `2024-12-09 write report @week due:2024-12-15 goal:week
+x 2024-12-10 2024-12-09 call Bob +work @week due:2024-12-15 goal:week
+2024-12-09 plan q1 +work @week due:2024-12-15 goal:week
+2024-12-09 (A) pay rent @day due:2024-12-09 goal:day
+`
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	read, err := Read(&out, updated)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	for n := range read {
+		read[n].Updated = updated
+	}
+
+	if !reflect.DeepEqual(goals, read) {
+		t.Errorf("expected %v, got %v", goals, read)
+	}
+}
+
+func TestRead(t *testing.T) {
+	now := time.Date(2024, 12, 10, 12, 0, 0, 0, time.Local)
+	input := `(A) 2024-12-09 call Mom +family @phone
+x 2024-12-10 2024-12-09 buy milk +family
+plan the week @week
+
+2024-12-11 unrelated
+`
+
+	goals, err := Read(strings.NewReader(input), now)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	contents := make([]string, 0)
+	for _, goal := range goals {
+		contents = append(contents, model.PeriodName(goal.Period)+" "+goal.FormatStart()+": "+goal.Content)
+	}
+
+	expected := []string{
+		"Day 2024-12-09 Monday: # family\n* [ ] (A) call Mom @phone\n* [x] buy milk",
+		"Week 2024-12-09 W50: * [ ] plan the week",
+		"Day 2024-12-11 Wednesday: * [ ] unrelated",
+	}
+	if !reflect.DeepEqual(expected, contents) {
+		t.Errorf("expected %q, got %q", expected, contents)
+	}
+}
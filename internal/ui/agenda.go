@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+	"log"
+	"time"
+)
+
+// currentGoals finds goals of the periods containing the date, including goals padded by the repository that aren't
+// stored yet, so their ids change on every read and they have to be found by date
+func currentGoals(ctx context.Context, goalsRepository goalsRepository, periods []model.Period, dt time.Time) []model.Goal {
+	current := make([]model.Goal, 0, len(periods))
+	for _, period := range periods {
+		goals, err := goalsRepository.FindForPeriod(ctx, period)
+		if err != nil {
+			log.Fatalf("failed to find goals: %v", err)
+		}
+
+		for _, goal := range goals {
+			if goal.CompareStart(dt) == 0 {
+				current = append(current, goal)
+				break
+			}
+		}
+	}
+
+	return current
+}
+
+// agendaRow is either a header of a goal or its checklist item
+type agendaRow struct {
+	goal   int
+	item   model.ChecklistItem
+	header bool
+}
+
+// agendaRows lists unchecked items grouped by goals, goals without them are skipped
+func agendaRows(goals []model.Goal) []agendaRow {
+	rows := make([]agendaRow, 0)
+	for n, goal := range goals {
+		header := len(rows)
+		for _, item := range goal.Checklist() {
+			if item.Done {
+				continue
+			}
+
+			if header == len(rows) {
+				rows = append(rows, agendaRow{goal: n, header: true})
+			}
+			rows = append(rows, agendaRow{goal: n, item: item})
+		}
+	}
+
+	return rows
+}
+
+type AgendaDialogProps struct {
+	app   *tview.Application
+	theme theme.Theme
+	goals []model.Goal
+	// onToggle stores the goal with the checked or unchecked item
+	onToggle func(goal model.Goal) error
+	onJump   func(goal model.Goal, line int) error
+	onClose  func()
+}
+
+// AgendaDialog shows open items of the current goals, checked items stay until the agenda is opened again
+type AgendaDialog struct {
+	AgendaDialogProps
+
+	Primitive tview.Primitive
+
+	table *tview.Table
+	rows  []agendaRow
+}
+
+func NewAgendaDialog(props AgendaDialogProps) *AgendaDialog {
+	d := &AgendaDialog{AgendaDialogProps: props, rows: agendaRows(props.goals)}
+	d.initPrimitive()
+	d.render()
+	// the first row is a header
+	d.table.Select(1, 0)
+	return d
+}
+
+func (d *AgendaDialog) initPrimitive() {
+	d.table = tview.NewTable().SetSelectable(true, false)
+	d.table.SetSelectedStyle(tcell.StyleDefault.Background(d.theme.Selection).Foreground(d.theme.Text))
+	d.table.SetBorder(true).
+		SetTitle("Agenda: space to check, enter to open the goal").
+		SetTitleColor(d.theme.PanelTitle).
+		SetBorderColor(d.theme.FocusedBorder)
+	d.table.SetSelectedFunc(func(row, column int) { d.jump(row) })
+	d.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc, event.Key() == tcell.KeyRune && event.Rune() == 'q':
+			d.onClose()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+			row, _ := d.table.GetSelection()
+			d.toggle(row)
+			return nil
+		}
+
+		return event
+	})
+
+	d.Primitive = centered(d.table, 80, 20)
+}
+
+func (d *AgendaDialog) render() {
+	d.table.Clear()
+
+	if len(d.rows) == 0 {
+		d.table.SetCell(0, 0, tview.NewTableCell("No open items in the current goals").
+			SetTextColor(d.theme.Placeholder).
+			SetSelectable(false))
+		return
+	}
+
+	for n, row := range d.rows {
+		goal := d.goals[row.goal]
+		if row.header {
+			title := fmt.Sprintf("%s %s", model.PeriodName(goal.Period), goal.FormatStart())
+			d.table.SetCell(n, 0, tview.NewTableCell(tview.Escape(title)).
+				SetTextColor(d.theme.PanelTitle).
+				SetSelectable(false))
+			continue
+		}
+
+		box := "[ ]"
+		if row.item.Done {
+			box = "[x]"
+		}
+		d.table.SetCell(n, 0, tview.NewTableCell(tview.Escape(fmt.Sprintf("  %s %s", box, row.item.Text))).
+			SetTextColor(d.theme.Text).
+			SetExpansion(1))
+	}
+}
+
+func (d *AgendaDialog) toggle(position int) {
+	if position < 0 || position >= len(d.rows) || d.rows[position].header {
+		return
+	}
+
+	row := &d.rows[position]
+	goal := d.goals[row.goal]
+	if !goal.SetDone(row.item.Line, !row.item.Done) {
+		return
+	}
+
+	if err := d.onToggle(goal); err != nil {
+		d.table.SetTitle(err.Error())
+		return
+	}
+
+	d.goals[row.goal] = goal
+	row.item.Done = !row.item.Done
+	d.render()
+}
+
+func (d *AgendaDialog) jump(position int) {
+	if position < 0 || position >= len(d.rows) || d.rows[position].header {
+		return
+	}
+
+	row := d.rows[position]
+	if err := d.onJump(d.goals[row.goal], row.item.Line); err != nil {
+		d.table.SetTitle(err.Error())
+	}
+}
+
+func (d *AgendaDialog) Focus() {
+	d.app.SetFocus(d.table)
+}
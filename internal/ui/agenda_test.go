@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"github.com/nvbn/termonizer/internal/model"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCurrentGoals(t *testing.T) {
+	dt := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+
+	year := model.NewGoalForYear(dt)
+	year.ID = "year"
+	previousWeek := model.NewGoalForWeek(dt.AddDate(0, 0, -7))
+	previousWeek.ID = "previous-week"
+	week := model.NewGoalForWeek(dt)
+	week.ID = "week"
+	day := model.NewGoalForDay(dt)
+	day.ID = "day"
+
+	repository := &goalsRepositoryMock{goals: []model.Goal{day, previousWeek, week, year}}
+
+	current := currentGoals(t.Context(), repository, model.Periods, dt)
+	ids := make([]string, 0, len(current))
+	for _, goal := range current {
+		ids = append(ids, goal.ID)
+	}
+
+	// there's no stored quarter
+	expected := []string{"year", "week", "day"}
+	if !reflect.DeepEqual(expected, ids) {
+		t.Errorf("expected %v, got %v", expected, ids)
+	}
+}
+
+func TestAgendaRows(t *testing.T) {
+	goals := []model.Goal{
+		{Period: model.Year, Content: "* [x] done\n* [ ] hire"},
+		{Period: model.Week, Content: "* [x] everything is done"},
+		{Period: model.Day, Content: "notes\n* [ ] standup\n- [ ] review"},
+	}
+
+	expected := []agendaRow{
+		{goal: 0, header: true},
+		{goal: 0, item: model.ChecklistItem{Line: 1, Text: "hire"}},
+		{goal: 2, header: true},
+		{goal: 2, item: model.ChecklistItem{Line: 1, Text: "standup"}},
+		{goal: 2, item: model.ChecklistItem{Line: 2, Text: "review"}},
+	}
+
+	if rows := agendaRows(goals); !reflect.DeepEqual(expected, rows) {
+		t.Errorf("expected %v, got %v", expected, rows)
+	}
+}
@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/model"
+	"log"
+	"sync"
+	"time"
+)
+
+// autosaver decides when edited goals are written according to the autosave policy
+type autosaver struct {
+	policy config.AutosavePolicy
+	delay  time.Duration
+	save   func(ctx context.Context, goal model.Goal) error
+
+	// saving is held around saves, so a timer save never overwrites a newer version saved from the ui
+	saving  sync.Mutex
+	mu      sync.Mutex
+	pending map[string]model.Goal
+	timers  map[string]*time.Timer
+}
+
+func newAutosaver(policy config.AutosavePolicy, delay time.Duration, save func(ctx context.Context, goal model.Goal) error) *autosaver {
+	return &autosaver{
+		policy:  policy,
+		delay:   delay,
+		save:    save,
+		pending: make(map[string]model.Goal),
+		timers:  make(map[string]*time.Timer),
+	}
+}
+
+func (a *autosaver) Changed(ctx context.Context, goal model.Goal) {
+	if a.policy == config.AutosaveImmediate {
+		a.saving.Lock()
+		defer a.saving.Unlock()
+		a.saveGoal(ctx, goal)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.pending[goal.ID] = goal
+
+	if a.policy == config.AutosaveDelayed {
+		if timer, ok := a.timers[goal.ID]; ok {
+			timer.Stop()
+		}
+		a.timers[goal.ID] = time.AfterFunc(a.delay, func() { a.flushGoal(ctx, goal.ID) })
+	}
+}
+
+// Blurred saves pending changes of the goal, leaving an editor shouldn't wait for the delay
+func (a *autosaver) Blurred(ctx context.Context, goalID string) {
+	a.flushGoal(ctx, goalID)
+}
+
+func (a *autosaver) flushGoal(ctx context.Context, goalID string) {
+	a.saving.Lock()
+	defer a.saving.Unlock()
+
+	a.mu.Lock()
+	goal, ok := a.pending[goalID]
+	delete(a.pending, goalID)
+	if timer, hasTimer := a.timers[goalID]; hasTimer {
+		timer.Stop()
+		delete(a.timers, goalID)
+	}
+	a.mu.Unlock()
+
+	if ok {
+		a.saveGoal(ctx, goal)
+	}
+}
+
+// Flush saves all pending changes after a save in progress, should be called on exit
+func (a *autosaver) Flush(ctx context.Context) {
+	a.saving.Lock()
+	defer a.saving.Unlock()
+
+	a.mu.Lock()
+	for id, timer := range a.timers {
+		timer.Stop()
+		delete(a.timers, id)
+	}
+	pending := a.pending
+	a.pending = make(map[string]model.Goal)
+	a.mu.Unlock()
+
+	for _, goal := range pending {
+		a.saveGoal(ctx, goal)
+	}
+}
+
+func (a *autosaver) saveGoal(ctx context.Context, goal model.Goal) {
+	if err := a.save(ctx, goal); err != nil {
+		log.Fatalf("failed to update goal: %s", err)
+	}
+}
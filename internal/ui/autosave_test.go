@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/model"
+	"sync"
+	"testing"
+	"time"
+)
+
+type savedGoals struct {
+	mu    sync.Mutex
+	goals []model.Goal
+}
+
+func (s *savedGoals) save(ctx context.Context, goal model.Goal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.goals = append(s.goals, goal)
+	return nil
+}
+
+func (s *savedGoals) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.goals)
+}
+
+func TestAutosaver_Immediate(t *testing.T) {
+	saved := &savedGoals{}
+	a := newAutosaver(config.AutosaveImmediate, time.Hour, saved.save)
+
+	a.Changed(t.Context(), model.Goal{ID: "first", Content: "a"})
+	a.Changed(t.Context(), model.Goal{ID: "first", Content: "ab"})
+
+	if saved.count() != 2 {
+		t.Errorf("expected 2 saves, got %d", saved.count())
+	}
+}
+
+func TestAutosaver_Delayed(t *testing.T) {
+	saved := &savedGoals{}
+	a := newAutosaver(config.AutosaveDelayed, 10*time.Millisecond, saved.save)
+
+	a.Changed(t.Context(), model.Goal{ID: "first", Content: "a"})
+	a.Changed(t.Context(), model.Goal{ID: "first", Content: "ab"})
+
+	if saved.count() != 0 {
+		t.Errorf("expected no saves before the delay, got %d", saved.count())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for saved.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if saved.count() != 1 || saved.goals[0].Content != "ab" {
+		t.Errorf("expected only the last change to be saved, got %v", saved.goals)
+	}
+}
+
+func TestAutosaver_Blur(t *testing.T) {
+	saved := &savedGoals{}
+	a := newAutosaver(config.AutosaveBlur, time.Hour, saved.save)
+
+	a.Changed(t.Context(), model.Goal{ID: "first", Content: "a"})
+	a.Changed(t.Context(), model.Goal{ID: "second", Content: "b"})
+
+	if saved.count() != 0 {
+		t.Errorf("expected no saves before blur, got %d", saved.count())
+	}
+
+	a.Blurred(t.Context(), "first")
+	if saved.count() != 1 {
+		t.Errorf("expected 1 save after blur, got %d", saved.count())
+	}
+
+	a.Flush(t.Context())
+	if saved.count() != 2 {
+		t.Errorf("expected 2 saves after flush, got %d", saved.count())
+	}
+}
+
+func TestAutosaver_Delayed_FlushWaitsForSave(t *testing.T) {
+	saved := &savedGoals{}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	save := func(ctx context.Context, goal model.Goal) error {
+		if goal.Content == "a" {
+			close(started)
+			<-release
+		}
+		return saved.save(ctx, goal)
+	}
+	a := newAutosaver(config.AutosaveDelayed, time.Millisecond, save)
+
+	a.Changed(t.Context(), model.Goal{ID: "first", Content: "a"})
+	<-started
+	a.Changed(t.Context(), model.Goal{ID: "first", Content: "ab"})
+
+	flushed := make(chan struct{})
+	go func() {
+		a.Flush(t.Context())
+		close(flushed)
+	}()
+
+	select {
+	case <-flushed:
+		t.Fatal("expected flush to wait for the save in progress")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-flushed
+
+	if saved.count() != 2 || saved.goals[0].Content != "a" || saved.goals[1].Content != "ab" {
+		t.Errorf("expected the newer change to be saved last, got %v", saved.goals)
+	}
+}
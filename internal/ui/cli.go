@@ -2,72 +2,218 @@ package ui
 
 import (
 	"context"
+	"fmt"
 	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/config"
 	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/org"
+	"github.com/nvbn/termonizer/internal/reminders"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/nvbn/termonizer/internal/todotxt"
 	"github.com/rivo/tview"
+	"io"
 	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const exitEscPressThreshold = time.Second
 
+const statusFlashDuration = 30 * time.Second
+
+const (
+	mainPage      = "main"
+	recurringPage = "recurring"
+	palettePage   = "palette"
+	helpPage      = "help"
+	zenPage       = "zen"
+	comparePage   = "compare"
+	agendaPage    = "agenda"
+)
+
+type Options struct {
+	Theme             theme.Theme
+	Keymap            *Keymap
+	Periods           []model.Period
+	Placeholder       string
+	FuturePlaceholder string
+	Autosave          config.AutosavePolicy
+	AutosaveDelay     time.Duration
+	EditingMode       config.EditingMode
+	ZenWidth          int
+	ZenParent         bool
+	// NotifyCommand is run on reminders in addition to the status bar
+	NotifyCommand []string
+}
+
 type CLI struct {
-	app                *tview.Application
-	timeNow            func() time.Time
-	goalsRepository    goalsRepository
-	settingsRepository settingsRepository
-	container          *tview.Flex
-	panels             []*PeriodPanel
-	currentFocus       int
-	lastEscapePress    time.Time
+	Options
+
+	app                 *tview.Application
+	timeNow             func() time.Time
+	goalsRepository     goalsRepository
+	settingsRepository  settingsRepository
+	recurringRepository recurringRepository
+	autosaver           *autosaver
+	history             *history
+	pages               *tview.Pages
+	root                *tview.Flex
+	container           *tview.Flex
+	status              *tview.TextView
+	statusFlashes       int // a flash hides the status bar only when no newer one is shown
+	tabs                *tview.TextView
+	panels              []*PeriodPanel
+	layout              model.Layout // applied layout, auto is already resolved
+	width               int
+	currentFocus        int
+	lastEscapePress     time.Time
 }
 
 func NewCLI(
 	ctx context.Context,
 	timeNow func() time.Time,
+	options Options,
 	goalsRepository goalsRepository,
 	settingsRepository settingsRepository,
+	recurringRepository recurringRepository,
 ) *CLI {
 	c := &CLI{
-		goalsRepository:    goalsRepository,
-		settingsRepository: settingsRepository,
-		timeNow:            timeNow,
+		Options:             options,
+		goalsRepository:     goalsRepository,
+		settingsRepository:  settingsRepository,
+		recurringRepository: recurringRepository,
+		timeNow:             timeNow,
+		autosaver:           newAutosaver(options.Autosave, options.AutosaveDelay, goalsRepository.Update),
+		history:             newHistory(timeNow, goalsRepository.Revisions),
 	}
 	c.init(ctx)
 	return c
 }
 
 func (c *CLI) init(ctx context.Context) {
+	applyTheme(c.Theme)
 	c.app = tview.NewApplication()
-	c.app.SetInputCapture(c.handleHotkeys)
+	c.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey { return c.handleHotkeys(ctx, event) })
 	c.render(ctx)
-	c.app.SetRoot(c.container, true).
+	// the auto layout depends on the terminal size, which is known only when drawing
+	c.app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		width, _ := screen.Size()
+		c.updateLayout(width)
+		return false
+	})
+	c.pages = tview.NewPages().AddPage(mainPage, c.root, true, true)
+	c.app.SetRoot(c.pages, true).
 		EnableMouse(true).
 		EnablePaste(true).
 		SetFocus(c.panels[len(c.panels)-1].PrimitiveInFocus())
 }
 
 func (c *CLI) render(ctx context.Context) {
-	c.container = tview.NewFlex().SetDirection(tview.FlexColumn)
+	c.container = tview.NewFlex()
 
-	c.panels = make([]*PeriodPanel, len(model.Periods))
+	// the status bar is hidden until there's something to show
+	c.status = tview.NewTextView()
+	c.status.SetTextColor(c.Theme.Text).SetBackgroundColor(c.Theme.Selection)
+	c.root = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(c.container, 0, 1, false).
+		AddItem(c.status, 0, 0, false)
 
-	for n, period := range model.Periods {
+	c.tabs = tview.NewTextView()
+	c.tabs.SetRegions(true)
+	c.tabs.SetDynamicColors(true)
+	c.tabs.SetWrap(false)
+	c.tabs.SetTextColor(c.Theme.PanelTitle)
+	c.tabs.SetText(tabsText(c.Periods))
+	// regions are highlighted on click
+	c.tabs.SetHighlightedFunc(func(added, removed, remaining []string) {
+		if len(added) == 0 {
+			return
+		}
+
+		if n, err := strconv.Atoi(added[0]); err == nil && n != c.currentFocus {
+			c.panels[n].Focus()
+		}
+	})
+
+	c.panels = make([]*PeriodPanel, len(c.Periods))
+
+	for n, period := range c.Periods {
 		panel := NewPeriodPanel(ctx, PeriodPanelProps{
 			app:                c.app,
 			timeNow:            c.timeNow,
+			theme:              c.Theme,
+			keymap:             c.Keymap,
+			placeholder:        c.Placeholder,
+			futurePlaceholder:  c.FuturePlaceholder,
+			autosaver:          c.autosaver,
+			history:            c.history,
+			editingMode:        c.EditingMode,
 			period:             period,
 			goalsRepository:    c.goalsRepository,
 			settingsRepository: c.settingsRepository,
-			onFocus:            func() { c.currentFocus = n },
+			onFocus:            func() { c.onPanelFocus(n) },
+			onSend:             func(editor *GoalEditor, move bool) { c.showSendTargets(ctx, editor, move) },
+			isPreviewEnabled:   c.settingsRepository.GetMarkdownPreview,
 		})
-		c.container.AddItem(panel.Primitive, 0, 1, false)
 		c.panels[n] = panel
 	}
 }
 
-func (c *CLI) handleHotkeys(event *tcell.EventKey) *tcell.EventKey {
-	if event.Key() == tcell.KeyEsc {
+func (c *CLI) onPanelFocus(n int) {
+	c.currentFocus = n
+	c.tabs.Highlight(strconv.Itoa(n))
+
+	// only the focused panel is visible with tabs
+	if c.layout == model.LayoutTabs {
+		c.arrange()
+	}
+}
+
+// updateLayout rearranges panels only when the layout for the width changes, as it happens on every draw
+func (c *CLI) updateLayout(width int) {
+	c.width = width
+	if layout := effectiveLayout(c.settingsRepository.GetLayout(), width); layout != c.layout {
+		c.layout = layout
+		c.arrange()
+	}
+}
+
+func (c *CLI) arrange() {
+	c.container.Clear()
+
+	switch c.layout {
+	case model.LayoutTabs:
+		c.container.SetDirection(tview.FlexRow).
+			AddItem(c.tabs, 1, 0, false).
+			AddItem(c.panels[c.currentFocus].Primitive, 0, 1, false)
+	case model.LayoutRows:
+		c.container.SetDirection(tview.FlexRow)
+		for _, panel := range c.panels {
+			c.container.AddItem(panel.Primitive, 0, 1, false)
+		}
+	default:
+		c.container.SetDirection(tview.FlexColumn)
+		for _, panel := range c.panels {
+			c.container.AddItem(panel.Primitive, 0, 1, false)
+		}
+	}
+}
+
+func (c *CLI) switchLayout(ctx context.Context) {
+	layout := nextLayout(c.settingsRepository.GetLayout())
+	if err := c.settingsRepository.SetLayout(ctx, layout); err != nil {
+		log.Fatalf("failed to switch layout: %v", err)
+	}
+
+	c.updateLayout(c.width)
+}
+
+func (c *CLI) handleHotkeys(ctx context.Context, event *tcell.EventKey) *tcell.EventKey {
+	// vim modes are left with esc, so it doesn't count towards the exit
+	if event.Key() == tcell.KeyEsc && !c.consumesEscape() {
 		log.Printf("hotkey: esc")
 
 		now := c.timeNow()
@@ -84,21 +230,532 @@ func (c *CLI) handleHotkeys(event *tcell.EventKey) *tcell.EventKey {
 		return tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModNone)
 	}
 
-	if event.Key() == tcell.KeyLeft && event.Modifiers()&tcell.ModShift != 0 && event.Modifiers()&tcell.ModAlt != 0 {
-		log.Printf("hotkey: option shift left")
-		c.focusLeft()
+	// only the focused goal is shown in the zen mode, so other commands are disabled
+	if page, _ := c.pages.GetFrontPage(); page == zenPage {
+		if c.Keymap.Is(event, ActionZen) {
+			log.Printf("hotkey: %s", ActionZen)
+			c.toggleZen(ctx)
+			return nil
+		}
+
+		return event
+	}
+
+	// dialogs handle keys by themselves
+	if page, _ := c.pages.GetFrontPage(); page != mainPage {
+		return event
+	}
+
+	if runCommand(c.Keymap, event, c.commands(ctx)) {
 		return nil
 	}
 
-	if event.Key() == tcell.KeyRight && event.Modifiers()&tcell.ModShift != 0 && event.Modifiers()&tcell.ModAlt != 0 {
-		log.Printf("hotkey: option shift right")
-		c.focusRight()
+	if event.Key() == tcell.KeyRune && event.Rune() == '?' && event.Modifiers() == tcell.ModNone &&
+		!c.panels[c.currentFocus].AcceptsText() {
+		log.Printf("hotkey: help")
+		c.showHelp(ctx)
 		return nil
 	}
 
 	return event
 }
 
+func (c *CLI) commands(ctx context.Context) []command {
+	return []command{
+		{ActionFocusLeft, "Focus the longer period on the left", c.focusLeft},
+		{ActionFocusRight, "Focus the shorter period on the right", c.focusRight},
+		{ActionTogglePreview, "Toggle rendered preview of not focused goals", func() { c.togglePreview(ctx) }},
+		{ActionRecurring, "Manage items added to new goals", func() { c.showRecurring(ctx) }},
+		{ActionHelp, "Show this help", func() { c.showHelp(ctx) }},
+		{ActionPalette, "Show all commands", func() { c.showCommandPalette(ctx) }},
+		{ActionSearch, "Search goals", func() { c.showSearch(ctx) }},
+		{ActionGoToDate, "Go to goals of a date", func() { c.showGoToDate(ctx) }},
+		{ActionExport, "Export goals to an org-mode or todo.txt file", func() { c.showExport(ctx) }},
+		{ActionZen, "Show only the focused goal, again to show all", func() { c.toggleZen(ctx) }},
+		{ActionCompare, "Compare the goal with another goal of the period", func() { c.showCompareChooser(ctx) }},
+		{ActionAgenda, "Show open items of the current goals", func() { c.showAgenda(ctx) }},
+		{ActionLayout, "Switch the layout: auto, columns, tabs or rows", func() { c.switchLayout(ctx) }},
+	}
+}
+
+func (c *CLI) consumesEscape() bool {
+	if page, _ := c.pages.GetFrontPage(); page != mainPage {
+		return false
+	}
+
+	return c.panels[c.currentFocus].ConsumesEscape()
+}
+
+func (c *CLI) togglePreview(ctx context.Context) {
+	if err := c.settingsRepository.SetMarkdownPreview(ctx, !c.settingsRepository.GetMarkdownPreview()); err != nil {
+		log.Fatalf("failed to toggle markdown preview: %v", err)
+	}
+
+	for _, panel := range c.panels {
+		panel.UpdatePreview()
+	}
+}
+
+func (c *CLI) showRecurring(ctx context.Context) {
+	dialog := NewRecurringDialog(ctx, RecurringDialogProps{
+		app:                 c.app,
+		theme:               c.Theme,
+		recurringRepository: c.recurringRepository,
+		onClose: func() {
+			c.pages.RemovePage(recurringPage)
+			c.panels[c.currentFocus].Focus()
+		},
+	})
+
+	c.pages.AddPage(recurringPage, dialog.Primitive, true, true)
+	dialog.Focus()
+}
+
+// toggleZen shows the focused goal alone, the editor is shared with its list, so the state is kept
+func (c *CLI) toggleZen(ctx context.Context) {
+	if page, _ := c.pages.GetFrontPage(); page == zenPage {
+		c.pages.SwitchToPage(mainPage)
+		c.pages.RemovePage(zenPage)
+		c.panels[c.currentFocus].Focus()
+		return
+	}
+
+	editor := c.panels[c.currentFocus].goalsList.EditorInFocus()
+
+	var parent *model.Goal
+	if c.ZenParent {
+		// the parent could have unsaved changes
+		c.autosaver.Flush(ctx)
+		if goal, ok := parentGoal(ctx, c.goalsRepository, editor.goal); ok {
+			parent = &goal
+		}
+	}
+
+	view := NewZenView(ZenViewProps{
+		theme:  c.Theme,
+		editor: editor,
+		parent: parent,
+		width:  c.ZenWidth,
+	})
+
+	c.pages.AddPage(zenPage, view.Primitive, true, false)
+	c.pages.SwitchToPage(zenPage)
+	editor.Focus()
+}
+
+// showCompareChooser lists other goals of the period, the previous one goes first as it's compared the most often
+func (c *CLI) showCompareChooser(ctx context.Context) {
+	// other goals could have unsaved changes
+	c.autosaver.Flush(ctx)
+
+	focused := c.panels[c.currentFocus].goalsList.EditorInFocus().goal
+	goals, err := c.goalsRepository.FindForPeriod(ctx, focused.Period)
+	if err != nil {
+		log.Fatalf("failed to find goals: %v", err)
+	}
+
+	position := slices.IndexFunc(goals, func(goal model.Goal) bool { return goal.ID == focused.ID })
+	others := slices.Concat(goals[position+1:], goals[:max(position, 0)])
+
+	c.showPalette("Compare with", "type to filter by date", func(query string) []paletteItem {
+		items := make([]paletteItem, 0, len(others))
+		for _, goal := range others {
+			title := goal.FormatStart()
+			if _, ok := fuzzyMatch(query, title); !ok {
+				continue
+			}
+
+			items = append(items, paletteItem{
+				title: title,
+				hint:  matchingLine(goal.Content, ""),
+				run: func() error {
+					c.closePalette()
+					c.showCompare(focused, goal)
+					return nil
+				},
+			})
+		}
+		return items
+	})
+}
+
+// showCompare shows the older goal on the left, so removed and added lines read in order
+func (c *CLI) showCompare(a model.Goal, b model.Goal) {
+	if a.Start.After(b.Start) {
+		a, b = b, a
+	}
+
+	view := NewCompareView(CompareViewProps{
+		app:   c.app,
+		theme: c.Theme,
+		old:   a,
+		new:   b,
+		onClose: func() {
+			c.pages.RemovePage(comparePage)
+			c.panels[c.currentFocus].Focus()
+		},
+	})
+
+	c.pages.AddPage(comparePage, view.Primitive, true, true)
+	view.Focus()
+}
+
+// showAgenda lists open items of the current year, quarter, week and day, checking them changes goals right away
+func (c *CLI) showAgenda(ctx context.Context) {
+	// goals could have unsaved changes
+	c.autosaver.Flush(ctx)
+
+	closeAgenda := func() {
+		c.pages.RemovePage(agendaPage)
+		c.panels[c.currentFocus].Focus()
+	}
+
+	dialog := NewAgendaDialog(AgendaDialogProps{
+		app:      c.app,
+		theme:    c.Theme,
+		goals:    currentGoals(ctx, c.goalsRepository, model.Periods, c.timeNow()),
+		onToggle: func(goal model.Goal) error { return c.updateGoal(ctx, goal) },
+		onJump: func(goal model.Goal, line int) error {
+			editor, err := c.showGoalAt(ctx, goal.Period, goal.Start)
+			if err != nil {
+				return err
+			}
+
+			c.pages.RemovePage(agendaPage)
+			editor.SelectLine(line)
+			editor.Focus()
+			return nil
+		},
+		onClose: closeAgenda,
+	})
+
+	c.pages.AddPage(agendaPage, dialog.Primitive, true, true)
+	dialog.Focus()
+}
+
+// showGoalAt scrolls the panel of the period to the goal containing the date and returns its editor, goals are found
+// by date as ids of goals padded by the repository change on every read
+func (c *CLI) showGoalAt(ctx context.Context, period model.Period, dt time.Time) (*GoalEditor, error) {
+	panel, ok := c.panelForPeriod(period)
+	if !ok {
+		return nil, fmt.Errorf("%s isn't enabled", strings.ToLower(model.PeriodName(period)))
+	}
+
+	if panel.ShowDate(ctx, dt) {
+		if editor, ok := panel.goalsList.EditorAt(dt); ok {
+			return editor, nil
+		}
+	}
+
+	goal := model.NewGoal(period, dt)
+	return nil, fmt.Errorf("%s %s isn't found", strings.ToLower(model.PeriodName(period)), goal.FormatStart())
+}
+
+// updateGoal changes the goal through its editor when it's rendered, so the change could be undone and isn't
+// overwritten by the editor
+func (c *CLI) updateGoal(ctx context.Context, goal model.Goal) error {
+	found, editor, err := c.goalAt(ctx, goal.Period, goal.Start)
+	if err != nil {
+		return err
+	}
+
+	if editor != nil {
+		editor.SetContent(goal.Content)
+		return nil
+	}
+
+	// the goal could be padded by the repository with a different id
+	goal.ID = found.ID
+	return c.goalsRepository.Update(ctx, goal)
+}
+
+// showSendTargets lists goals the current lines of the editor could be moved or copied to
+func (c *CLI) showSendTargets(ctx context.Context, editor *GoalEditor, move bool) {
+	text := editor.editor.GetText()
+	_, start, end := editor.editor.GetSelection()
+	from, to := lineRange(text, start, end)
+	if strings.TrimSpace(text[from:to]) == "" {
+		return
+	}
+
+	title := "Copy to"
+	if move {
+		title = "Move to"
+	}
+
+	targets := sendTargets(editor.goal)
+	c.showPalette(title, "type to filter", func(query string) []paletteItem {
+		items := make([]paletteItem, 0, len(targets))
+		for _, target := range targets {
+			if _, ok := fuzzyMatch(query, target.title); !ok {
+				continue
+			}
+
+			items = append(items, paletteItem{
+				title: target.title,
+				run: func() error {
+					if err := c.sendLines(ctx, editor, from, to, target, move); err != nil {
+						return err
+					}
+
+					c.closePalette()
+					return nil
+				},
+			})
+		}
+		return items
+	})
+}
+
+// sendLines appends lines to the target goal and removes them from the source when moving, both goals are stored
+// at once and then shown in their editors
+func (c *CLI) sendLines(ctx context.Context, editor *GoalEditor, from int, to int, target sendTarget, move bool) error {
+	// stored goals have to match editors
+	c.autosaver.Flush(ctx)
+
+	source := editor.goal
+	goal, targetEditor, err := c.goalAt(ctx, target.period, target.start)
+	if err != nil {
+		return err
+	}
+
+	goal.Content = appendLines(goal.Content, source.Content[from:to])
+	changed := []model.Goal{goal}
+	if move {
+		source.Content = removeLines(source.Content, from, to)
+		changed = append(changed, source)
+	}
+
+	if err := c.goalsRepository.UpdateMany(ctx, changed); err != nil {
+		return fmt.Errorf("failed to update goals: %w", err)
+	}
+
+	if targetEditor != nil {
+		targetEditor.SetContent(goal.Content)
+	}
+
+	if move {
+		editor.SetContent(source.Content)
+		editor.editor.Select(from, from)
+	}
+
+	return nil
+}
+
+// goalAt finds the goal of the period containing the date with its editor, nil when it isn't rendered
+func (c *CLI) goalAt(ctx context.Context, period model.Period, dt time.Time) (model.Goal, *GoalEditor, error) {
+	panel, panelOk := c.panelForPeriod(period)
+	if panelOk {
+		if editor, ok := panel.goalsList.EditorAt(dt); ok {
+			return editor.goal, editor, nil
+		}
+	}
+
+	goals, err := c.goalsRepository.FindForPeriod(ctx, period)
+	if err != nil {
+		return model.Goal{}, nil, fmt.Errorf("failed to find goals: %w", err)
+	}
+
+	for _, goal := range goals {
+		if goal.CompareStart(dt) != 0 {
+			continue
+		}
+
+		if panelOk {
+			if editor, ok := panel.goalsList.Editor(goal.ID); ok {
+				return editor.goal, editor, nil
+			}
+		}
+
+		return goal, nil, nil
+	}
+
+	return model.NewGoal(period, dt), nil, nil
+}
+
+// showHelp lists hotkeys of the app and the focused panel and goal
+func (c *CLI) showHelp(ctx context.Context) {
+	list := c.panels[c.currentFocus].goalsList
+	dialog := NewHelpDialog(HelpDialogProps{
+		app:     c.app,
+		theme:   c.Theme,
+		keymap:  c.Keymap,
+		content: renderHelp(c.Keymap, helpGroups(ctx, c, list, list.EditorInFocus())),
+		onClose: func() {
+			c.pages.RemovePage(helpPage)
+			c.panels[c.currentFocus].Focus()
+		},
+	})
+
+	c.pages.AddPage(helpPage, dialog.Primitive, true, true)
+	dialog.Focus()
+}
+
+func (c *CLI) showPalette(title string, placeholder string, items func(query string) []paletteItem) {
+	dialog := NewPaletteDialog(PaletteDialogProps{
+		app:         c.app,
+		theme:       c.Theme,
+		title:       title,
+		placeholder: placeholder,
+		items:       items,
+		onClose:     c.closePalette,
+	})
+
+	c.pages.AddPage(palettePage, dialog.Primitive, true, true)
+	dialog.Focus()
+}
+
+func (c *CLI) closePalette() {
+	c.pages.RemovePage(palettePage)
+	c.panels[c.currentFocus].Focus()
+}
+
+// showCommandPalette lists commands of the app, the focused panel and the focused goal, so actions without working
+// bindings in the terminal are still available
+func (c *CLI) showCommandPalette(ctx context.Context) {
+	commands := slices.DeleteFunc(
+		append(c.commands(ctx), c.panels[c.currentFocus].commands(ctx)...),
+		func(cmd command) bool { return cmd.action == ActionPalette },
+	)
+
+	c.showPalette("Commands", "type to filter", func(query string) []paletteItem {
+		items := make([]paletteItem, 0, len(commands))
+		for _, cmd := range filterCommands(commands, query) {
+			items = append(items, paletteItem{
+				title: cmd.description,
+				hint:  formatKeys(c.Keymap.Keys(cmd.action)),
+				run: func() error {
+					log.Printf("palette: %s", cmd.action)
+					c.closePalette()
+					cmd.run()
+					return nil
+				},
+			})
+		}
+		return items
+	})
+}
+
+func (c *CLI) panelForPeriod(period model.Period) (*PeriodPanel, bool) {
+	for n, p := range c.Periods {
+		if p == period {
+			return c.panels[n], true
+		}
+	}
+
+	return nil, false
+}
+
+func (c *CLI) showSearch(ctx context.Context) {
+	c.showPalette("Search", "text to find", func(query string) []paletteItem {
+		if strings.TrimSpace(query) == "" {
+			return nil
+		}
+
+		found, err := c.goalsRepository.Search(ctx, query)
+		if err != nil {
+			log.Fatalf("failed to search goals: %v", err)
+		}
+
+		items := make([]paletteItem, 0, len(found))
+		for _, goal := range found {
+			items = append(items, paletteItem{
+				title: matchingLine(goal.Content, query),
+				hint:  fmt.Sprintf("%s %s", model.PeriodName(goal.Period), goal.FormatStart()),
+				run: func() error {
+					panel, ok := c.panelForPeriod(goal.Period)
+					if !ok {
+						return fmt.Errorf("%s isn't enabled", strings.ToLower(model.PeriodName(goal.Period)))
+					}
+
+					c.closePalette()
+					panel.ShowGoal(ctx, goal.ID)
+					return nil
+				},
+			})
+		}
+		return items
+	})
+}
+
+// showGoToDate scrolls every panel to goals containing the date and keeps the focused panel
+func (c *CLI) showGoToDate(ctx context.Context) {
+	c.showPalette("Go to date", "2024-12-31, today, tomorrow, yesterday, +7 or -7 days", func(query string) []paletteItem {
+		dt, ok := parseDate(query, c.timeNow())
+		if !ok {
+			return nil
+		}
+
+		return []paletteItem{{
+			title: dt.Format("Monday, January 2 2006"),
+			run: func() error {
+				focused := c.currentFocus
+				c.closePalette()
+				for _, panel := range c.panels {
+					panel.ShowDate(ctx, dt)
+				}
+				c.panels[focused].Focus()
+				return nil
+			},
+		}}
+	})
+}
+
+func (c *CLI) showExport(ctx context.Context) {
+	formats := []struct {
+		name  string
+		write func(io.Writer, []model.Goal) error
+	}{
+		{"org-mode", org.Write},
+		{"todo.txt", todotxt.Write},
+	}
+
+	c.showPalette("Export", "path to the file", func(query string) []paletteItem {
+		path := os.ExpandEnv(strings.TrimSpace(query))
+		if path == "" {
+			return nil
+		}
+
+		items := make([]paletteItem, 0, len(formats))
+		for _, format := range formats {
+			items = append(items, paletteItem{
+				title: fmt.Sprintf("Export to %s as %s", path, format.name),
+				run: func() error {
+					if err := c.export(ctx, path, format.write); err != nil {
+						return err
+					}
+
+					c.closePalette()
+					return nil
+				},
+			})
+		}
+		return items
+	})
+}
+
+func (c *CLI) export(ctx context.Context, path string, write func(io.Writer, []model.Goal) error) error {
+	// pending changes aren't stored yet
+	c.autosaver.Flush(ctx)
+
+	goals, err := c.goalsRepository.All(ctx)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	if err := write(f, goals); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return f.Close()
+}
+
 func (c *CLI) focusLeft() {
 	if c.currentFocus == 0 {
 		return
@@ -115,6 +772,40 @@ func (c *CLI) focusRight() {
 	c.panels[c.currentFocus+1].Focus()
 }
 
-func (c *CLI) Run() error {
+// flashStatus shows the text in the status bar for statusFlashDuration
+func (c *CLI) flashStatus(text string) {
+	c.statusFlashes++
+	flash := c.statusFlashes
+
+	c.status.SetText(text)
+	c.root.ResizeItem(c.status, 1, 0)
+
+	time.AfterFunc(statusFlashDuration, func() {
+		c.app.QueueUpdateDraw(func() {
+			if flash == c.statusFlashes {
+				c.root.ResizeItem(c.status, 0, 0)
+			}
+		})
+	})
+}
+
+// remind is called by the scheduler outside the event loop
+func (c *CLI) remind(reminder model.Reminder) {
+	c.app.QueueUpdateDraw(func() {
+		c.flashStatus(fmt.Sprintf("Reminder: %s %s", reminder.At.Format("15:04"), reminder.Text))
+	})
+
+	if err := reminders.Command(c.NotifyCommand)(reminder); err != nil {
+		log.Printf("failed to notify: %v", err)
+	}
+}
+
+func (c *CLI) Run(ctx context.Context) error {
+	defer c.autosaver.Flush(ctx)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go reminders.NewScheduler(c.timeNow, c.goalsRepository, c.remind).Run(ctx, reminders.CheckInterval)
+
 	return c.app.Run()
 }
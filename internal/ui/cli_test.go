@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
+	"testing"
+	"time"
+)
+
+// paddingGoalsRepositoryMock pads the next and the current days like the real repository, with new ids on every read
+type paddingGoalsRepositoryMock struct {
+	goalsRepositoryMock
+	dt      time.Time
+	content string
+}
+
+func (m *paddingGoalsRepositoryMock) FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error) {
+	found, err := m.goalsRepositoryMock.FindForPeriod(ctx, period)
+	if err != nil || period != model.Day {
+		return found, err
+	}
+
+	next := model.NewGoalForDay(m.dt.AddDate(0, 0, 1))
+	next.ID = uuid.NewString()
+	padded := []model.Goal{next}
+	if len(found) == 0 {
+		current := model.NewGoalForDay(m.dt)
+		current.ID = uuid.NewString()
+		current.Content = m.content
+		padded = append(padded, current)
+	}
+
+	return append(padded, found...), nil
+}
+
+func (m *paddingGoalsRepositoryMock) CountForPeriod(ctx context.Context, period model.Period) (int, error) {
+	found, _ := m.FindForPeriod(ctx, period)
+	return len(found), nil
+}
+
+type settingsRepositoryMock struct{}
+
+func (settingsRepositoryMock) GetAmountForPeriod(period model.Period) int { return 1 }
+
+func (settingsRepositoryMock) SetAmountForPeriod(ctx context.Context, period model.Period, amount int) error {
+	return nil
+}
+
+func (settingsRepositoryMock) GetMarkdownPreview() bool { return false }
+
+func (settingsRepositoryMock) SetMarkdownPreview(ctx context.Context, enabled bool) error { return nil }
+
+func (settingsRepositoryMock) GetLayout() model.Layout { return model.LayoutAuto }
+
+func (settingsRepositoryMock) SetLayout(ctx context.Context, layout model.Layout) error { return nil }
+
+type recurringRepositoryMock struct{}
+
+func (recurringRepositoryMock) List(ctx context.Context) ([]model.Recurring, error) { return nil, nil }
+
+func (recurringRepositoryMock) Add(ctx context.Context, schedule string, content string) (model.Recurring, error) {
+	return model.Recurring{}, nil
+}
+
+func (recurringRepositoryMock) Remove(ctx context.Context, id string) error { return nil }
+
+func TestCLI_Agenda_PaddedGoal(t *testing.T) {
+	dt := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+	dark, err := theme.NewThemes().Get("dark")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	keymap, err := NewKeymap(nil)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	repository := &paddingGoalsRepositoryMock{dt: dt, content: "* [ ] standup\n* [ ] review"}
+	c := NewCLI(
+		t.Context(),
+		func() time.Time { return dt },
+		Options{
+			Theme:         dark,
+			Keymap:        keymap,
+			Periods:       []model.Period{model.Day},
+			Autosave:      config.AutosaveImmediate,
+			AutosaveDelay: time.Second,
+		},
+		repository,
+		settingsRepositoryMock{},
+		recurringRepositoryMock{},
+	)
+
+	current := currentGoals(t.Context(), repository, c.Periods, dt)
+	if len(current) != 1 {
+		t.Fatalf("expected the padded goal, got %v", current)
+	}
+
+	rendered, ok := c.panels[0].goalsList.EditorAt(dt)
+	if !ok {
+		t.Fatal("expected the editor of the padded goal")
+	}
+
+	goal := current[0]
+	goal.Content = "* [x] standup\n* [ ] review"
+	if err := c.updateGoal(t.Context(), goal); err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if content := rendered.editor.GetText(); content != goal.Content {
+		t.Errorf("expected the toggled item in the rendered editor, got %q", content)
+	}
+
+	editor, err := c.showGoalAt(t.Context(), goal.Period, goal.Start)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	if editor != rendered {
+		t.Error("expected the rendered editor to be shown")
+	}
+
+	c.autosaver.Flush(t.Context())
+	if len(repository.goals) != 1 {
+		t.Errorf("expected a single stored goal, got %v", repository.goals)
+	}
+
+	if _, err := c.showGoalAt(t.Context(), goal.Period, dt.AddDate(1, 0, 0)); err == nil {
+		t.Error("expected an error for a goal that isn't shown")
+	}
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"cmp"
+	"github.com/gdamore/tcell/v2"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// command is an action with its handler, components list commands for hotkeys and the command palette
+type command struct {
+	action      Action
+	description string
+	run         func()
+}
+
+// runCommand runs the command bound to the key, false when there's none
+func runCommand(keymap *Keymap, event *tcell.EventKey, commands []command) bool {
+	for _, c := range commands {
+		if keymap.Is(event, c.action) {
+			log.Printf("hotkey: %s", c.action)
+			c.run()
+			return true
+		}
+	}
+
+	return false
+}
+
+// fuzzyMatch checks that runes of the query appear in the text in order ignoring case, matches at word starts
+// and consecutive matches score higher
+func fuzzyMatch(query string, text string) (int, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	text = strings.ToLower(text)
+
+	score := 0
+	previous := -1
+	pos := 0
+	for _, r := range query {
+		if unicode.IsSpace(r) {
+			continue
+		}
+
+		idx := strings.IndexRune(text[pos:], r)
+		if idx == -1 {
+			return 0, false
+		}
+
+		idx += pos
+		switch {
+		case idx == previous:
+			score += 3
+		case idx == 0 || !unicode.IsLetter(lastRune(text[:idx])):
+			score += 2
+		default:
+			score += 1
+		}
+
+		pos = idx + utf8.RuneLen(r)
+		previous = pos
+	}
+
+	return score, true
+}
+
+func lastRune(text string) rune {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return r
+}
+
+// filterCommands returns commands matching the query by the description or the action, the best matches first
+func filterCommands(commands []command, query string) []command {
+	type scored struct {
+		command command
+		score   int
+	}
+
+	matched := make([]scored, 0, len(commands))
+	for _, c := range commands {
+		score, ok := fuzzyMatch(query, c.description)
+		if actionScore, actionOk := fuzzyMatch(query, string(c.action)); actionOk && (!ok || actionScore > score) {
+			score, ok = actionScore, true
+		}
+
+		if ok {
+			matched = append(matched, scored{c, score})
+		}
+	}
+
+	slices.SortStableFunc(matched, func(a, b scored) int { return cmp.Compare(b.score, a.score) })
+
+	filtered := make([]command, 0, len(matched))
+	for _, m := range matched {
+		filtered = append(filtered, m.command)
+	}
+
+	return filtered
+}
+
+// matchingLine returns the first line containing the query to show it in search results, the first line when
+// the query is matched differently, e.g. in an encrypted goal
+func matchingLine(content string, query string) string {
+	lines := strings.Split(content, "\n")
+	for _, line := range lines {
+		if strings.Contains(strings.ToLower(line), strings.ToLower(query)) {
+			return strings.TrimSpace(line)
+		}
+	}
+
+	return strings.TrimSpace(lines[0])
+}
+
+// parseDate reads 2024-12-31, today, tomorrow, yesterday or a relative amount of days like +7 or -7
+func parseDate(query string, now time.Time) (time.Time, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	switch query {
+	case "":
+		return time.Time{}, false
+	case "today":
+		return now, true
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), true
+	case "yesterday":
+		return now.AddDate(0, 0, -1), true
+	}
+
+	if query[0] == '+' || query[0] == '-' {
+		days, err := strconv.Atoi(query)
+		if err != nil {
+			return time.Time{}, false
+		}
+
+		return now.AddDate(0, 0, days), true
+	}
+
+	dt, err := time.ParseInLocation("2006-01-02", query, now.Location())
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return dt, true
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+	"time"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	inputsExpecteds := []struct {
+		query string
+		text  string
+		ok    bool
+	}{
+		{"", "anything", true},
+		{"zoom", "Zoom in", true},
+		{"zi", "Zoom in", true},
+		{"ZOOM IN", "zoom in", true},
+		{"nz", "Zoom in", false},
+		{"undo x", "Undo", false},
+		{"пои", "Поиск", true},
+	}
+
+	for _, tt := range inputsExpecteds {
+		if _, ok := fuzzyMatch(tt.query, tt.text); ok != tt.ok {
+			t.Errorf("%q in %q: expected %v, got %v", tt.query, tt.text, tt.ok, ok)
+		}
+	}
+
+	wordStart, _ := fuzzyMatch("fp", "Focus the past goal")
+	middle, _ := fuzzyMatch("fp", "Toggle preview")
+	if wordStart <= middle {
+		t.Errorf("expected word starts to score higher, got %d and %d", wordStart, middle)
+	}
+}
+
+func TestFilterCommands(t *testing.T) {
+	commands := []command{
+		{ActionTogglePreview, "Toggle rendered preview of not focused goals", nil},
+		{ActionZoomIn, "Zoom in, decrease the amount of visible goals", nil},
+		{ActionZoomOut, "Zoom out, increase the amount of visible goals", nil},
+	}
+
+	inputsExpecteds := []struct {
+		query    string
+		expected []Action
+	}{
+		{"", []Action{ActionTogglePreview, ActionZoomIn, ActionZoomOut}},
+		// loosely matches other commands too, but they go after
+		{"zoom out", []Action{ActionZoomOut, ActionZoomIn}},
+		{"zoom_in", []Action{ActionZoomIn}},
+		{"preview", []Action{ActionTogglePreview}},
+		{"nothing", []Action{}},
+	}
+
+	for _, tt := range inputsExpecteds {
+		t.Run(tt.query, func(t *testing.T) {
+			filtered := filterCommands(commands, tt.query)
+			if len(filtered) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, filtered)
+			}
+
+			for n, c := range filtered {
+				if c.action != tt.expected[n] {
+					t.Errorf("expected %v at %d, got %v", tt.expected[n], n, c.action)
+				}
+			}
+		})
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	k, err := NewKeymap(nil)
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	ran := make([]Action, 0)
+	commands := []command{
+		{ActionPalette, "Show all commands", func() { ran = append(ran, ActionPalette) }},
+		{ActionExport, "Export", func() { ran = append(ran, ActionExport) }},
+	}
+
+	if !runCommand(k, tcell.NewEventKey(tcell.KeyCtrlP, 0, tcell.ModCtrl), commands) {
+		t.Error("expected the palette to run")
+	}
+
+	if runCommand(k, tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone), commands) {
+		t.Error("expected no command")
+	}
+
+	if len(ran) != 1 || ran[0] != ActionPalette {
+		t.Errorf("unexpected commands %v", ran)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 12, 10, 15, 0, 0, 0, time.UTC)
+
+	inputsExpecteds := []struct {
+		query    string
+		expected string
+		ok       bool
+	}{
+		{"today", "2024-12-10", true},
+		{" Tomorrow ", "2024-12-11", true},
+		{"yesterday", "2024-12-09", true},
+		{"+30", "2025-01-09", true},
+		{"-10", "2024-11-30", true},
+		{"2023-02-01", "2023-02-01", true},
+		{"", "", false},
+		{"+", "", false},
+		{"someday", "", false},
+	}
+
+	for _, tt := range inputsExpecteds {
+		dt, ok := parseDate(tt.query, now)
+		if ok != tt.ok {
+			t.Errorf("%q: expected %v, got %v", tt.query, tt.ok, ok)
+			continue
+		}
+
+		if ok && dt.Format("2006-01-02") != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.query, tt.expected, dt.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestMatchingLine(t *testing.T) {
+	content := "# Week\n* [ ] Write the Report\n* [x] review"
+
+	if line := matchingLine(content, "report"); line != "* [ ] Write the Report" {
+		t.Errorf("unexpected line %q", line)
+	}
+
+	if line := matchingLine(content, "missing"); line != "# Week" {
+		t.Errorf("expected the first line, got %q", line)
+	}
+}
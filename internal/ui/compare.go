@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/merge"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+	"strings"
+)
+
+type CompareViewProps struct {
+	app     *tview.Application
+	theme   theme.Theme
+	old     model.Goal
+	new     model.Goal
+	onClose func()
+}
+
+// CompareView shows two goals side by side, removed lines are highlighted in the older one and added in the newer
+type CompareView struct {
+	CompareViewProps
+
+	Primitive tview.Primitive
+
+	oldView *tview.TextView
+	newView *tview.TextView
+}
+
+func NewCompareView(props CompareViewProps) *CompareView {
+	v := &CompareView{CompareViewProps: props}
+	v.initPrimitive()
+	return v
+}
+
+func (v *CompareView) initView(goal model.Goal) *tview.TextView {
+	p := tview.NewTextView()
+	p.SetDynamicColors(true)
+	p.SetWrap(false)
+	p.SetTextColor(v.theme.Text)
+	p.SetBorder(true).
+		SetBorderColor(v.theme.Border).
+		SetTitle(fmt.Sprintf("%s %s", model.PeriodName(goal.Period), goal.FormatStart())).
+		SetTitleColor(v.theme.PanelTitle)
+	return p
+}
+
+func (v *CompareView) initPrimitive() {
+	v.oldView = v.initView(v.old)
+	v.newView = v.initView(v.new)
+	v.oldView.SetBorderColor(v.theme.FocusedBorder)
+
+	oldText, newText := renderDiff(v.theme, merge.Diff(v.old.Content, v.new.Content))
+	v.oldView.SetText(oldText)
+	v.newView.SetText(newText)
+
+	v.oldView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || (event.Key() == tcell.KeyRune && event.Rune() == 'q') {
+			v.onClose()
+			return nil
+		}
+
+		return event
+	})
+
+	layout := tview.NewFlex().
+		AddItem(v.oldView, 0, 1, true).
+		AddItem(v.newView, 0, 1, false)
+	// rows are aligned, so the newer goal follows the scroll of the focused older one
+	layout.SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+		v.newView.ScrollTo(v.oldView.GetScrollOffset())
+		return x, y, width, height
+	})
+
+	v.Primitive = layout
+}
+
+// renderDiff marks changed lines with colors and -/+, so they're distinguishable without colors too
+func renderDiff(theme theme.Theme, rows []merge.DiffRow) (string, string) {
+	render := func(out *strings.Builder, text string, kind merge.DiffKind) {
+		switch kind {
+		case merge.DiffRemoved:
+			fmt.Fprintf(out, "[%s]- %s[-]\n", theme.Removed.String(), tview.Escape(text))
+		case merge.DiffAdded:
+			fmt.Fprintf(out, "[%s]+ %s[-]\n", theme.Added.String(), tview.Escape(text))
+		case merge.DiffPadding:
+			out.WriteString("\n")
+		default:
+			fmt.Fprintf(out, "  %s\n", tview.Escape(text))
+		}
+	}
+
+	var oldOut, newOut strings.Builder
+	for _, row := range rows {
+		render(&oldOut, row.Old, row.OldKind)
+		render(&newOut, row.New, row.NewKind)
+	}
+
+	return oldOut.String(), newOut.String()
+}
+
+func (v *CompareView) Focus() {
+	v.app.SetFocus(v.oldView)
+}
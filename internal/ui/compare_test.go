@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/merge"
+	"github.com/nvbn/termonizer/internal/theme"
+	"testing"
+)
+
+func TestRenderDiff(t *testing.T) {
+	colors := theme.Theme{Added: tcell.ColorGreen, Removed: tcell.ColorRed}
+
+	oldText, newText := renderDiff(colors, merge.Diff("# Week\n* [ ] report\n* [ ] review", "# Week\n* [x] report\n* [ ] review\n* [ ] plan [q1]"))
+
+	// checkboxes look like color tags, so they're escaped
+	expectedOld := "  # Week\n[red]- * [ [] report[-]\n  * [ [] review\n\n"
+	if oldText != expectedOld {
+		t.Errorf("expected %q, got %q", expectedOld, oldText)
+	}
+
+	expectedNew := "  # Week\n[green]+ * [x[] report[-]\n  * [ [] review\n[green]+ * [ [] plan [q1[][-]\n"
+	if newText != expectedNew {
+		t.Errorf("expected %q, got %q", expectedNew, newText)
+	}
+}
@@ -9,9 +9,24 @@ type goalsRepository interface {
 	FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error)
 	CountForPeriod(ctx context.Context, period model.Period) (int, error)
 	Update(ctx context.Context, goals model.Goal) error
+	UpdateMany(ctx context.Context, goals []model.Goal) error
+	Delete(ctx context.Context, goal model.Goal) error
+	Revisions(ctx context.Context, goalID string) ([]model.Revision, error)
+	Search(ctx context.Context, query string) ([]model.Goal, error)
+	All(ctx context.Context) ([]model.Goal, error)
+}
+
+type recurringRepository interface {
+	List(ctx context.Context) ([]model.Recurring, error)
+	Add(ctx context.Context, schedule string, content string) (model.Recurring, error)
+	Remove(ctx context.Context, id string) error
 }
 
 type settingsRepository interface {
 	GetAmountForPeriod(period model.Period) int
 	SetAmountForPeriod(ctx context.Context, period model.Period, amount int) error
+	GetMarkdownPreview() bool
+	SetMarkdownPreview(ctx context.Context, enabled bool) error
+	GetLayout() model.Layout
+	SetLayout(ctx context.Context, layout model.Layout) error
 }
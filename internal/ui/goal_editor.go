@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/config"
 	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
 	"github.com/nvbn/termonizer/internal/utils"
 	"github.com/rivo/tview"
 	"golang.design/x/clipboard"
@@ -13,77 +15,214 @@ import (
 	"time"
 )
 
-const goalEditorPlaceholder = `* a things to do
-* a thing to achieve
-
---
-Some notes. This is just a placeholder in some opinionated format.
-`
-
-const futureGoalEditorPlaceholder = `Goals and notes for the future`
-
 type GoalEditorProps struct {
-	app             *tview.Application
-	timeNow         func() time.Time
-	goalsRepository goalsRepository
-	goal            model.Goal
-	onFocus         func()
+	app               *tview.Application
+	timeNow           func() time.Time
+	theme             theme.Theme
+	keymap            *Keymap
+	placeholder       string
+	futurePlaceholder string
+	autosaver         *autosaver
+	history           *history
+	editingMode       config.EditingMode
+	goalsRepository   goalsRepository
+	goal              model.Goal
+	onFocus           func()
+	onSend            func(editor *GoalEditor, move bool) // moves or copies the current lines to another goal
+	isPreviewEnabled  func() bool
 }
 
 type GoalEditor struct {
 	GoalEditorProps
 
-	Primitive *tview.TextArea
+	Primitive *tview.Flex
+
+	editor         *tview.TextArea
+	preview        *tview.TextView
+	previewToggled bool // inverts the global preview mode only for this editor
+	vim            *vim // nil without modal editing
+	restoring      bool // content from the history isn't recorded as a new change
 }
 
 func NewGoalEditor(ctx context.Context, props GoalEditorProps) *GoalEditor {
 	e := &GoalEditor{GoalEditorProps: props}
+	if props.editingMode == config.EditingVim {
+		e.vim = newVim(
+			func(text string) { clipboard.Write(clipboard.FmtText, []byte(text)) },
+			func() string { return string(clipboard.Read(clipboard.FmtText)) },
+		)
+	}
 	e.initPrimitive(ctx)
 	return e
 }
 
-func (e *GoalEditor) initPrimitive(ctx context.Context) {
-	p := tview.NewTextArea()
+func (e *GoalEditor) textColor() tcell.Color {
+	switch e.goal.CompareStart(e.timeNow()) {
+	case 1:
+		return e.theme.FutureText
+	case 0:
+		return e.theme.NowText
+	default:
+		return e.theme.PastText
+	}
+}
 
+func (e *GoalEditor) title() string {
+	title := e.goal.FormatStart()
 	switch e.goal.CompareStart(e.timeNow()) {
 	case 1:
-		p.SetTitle(fmt.Sprintf("%s (future)", e.goal.FormatStart()))
-		p.SetTitleColor(tcell.ColorBlue)
+		title = fmt.Sprintf("%s (future)", title)
 	case 0:
-		p.SetTitle(fmt.Sprintf("%s (now)", e.goal.FormatStart()))
+		title = fmt.Sprintf("%s (now)", title)
+	}
+
+	if e.goal.HasConflict() {
+		title = fmt.Sprintf("%s (sync conflict, keep one version and remove markers)", title)
+	}
+
+	if e.vim != nil {
+		title = fmt.Sprintf("%s [%s]", title, e.vim.modeName())
+	}
+
+	return title
+}
+
+func (e *GoalEditor) decorate(box *tview.Box) {
+	box.SetTitle(e.title())
+	switch e.goal.CompareStart(e.timeNow()) {
+	case 1:
+		box.SetTitleColor(e.theme.FutureTitle)
+	case 0:
+		box.SetTitleColor(e.theme.NowTitle)
 	case -1:
-		p.SetTitle(e.goal.FormatStart())
+		box.SetTitleColor(e.theme.PastTitle)
 	}
 
-	p.SetBorder(true)
+	box.SetBorder(true)
+	box.SetBorderColor(e.theme.Border)
+}
+
+func (e *GoalEditor) initPrimitive(ctx context.Context) {
+	e.initEditor(ctx)
+	e.initPreview()
+
+	e.Primitive = tview.NewFlex().SetDirection(tview.FlexRow)
+	e.UpdateMode()
+}
+
+func (e *GoalEditor) initPreview() {
+	p := tview.NewTextView()
+	e.decorate(p.Box)
+	p.SetDynamicColors(true)
+	p.SetTextColor(e.textColor())
+	p.SetWordWrap(true)
+	// the preview is read-only, so clicking or focusing it switches to editing
+	p.SetFocusFunc(e.Focus)
+
+	e.preview = p
+}
+
+func (e *GoalEditor) initEditor(ctx context.Context) {
+	p := tview.NewTextArea()
+	e.decorate(p.Box)
 	p.SetText(e.goal.Content, false)
+	p.SetTextStyle(tcell.StyleDefault.Background(e.theme.Background).Foreground(e.textColor()))
+	p.SetSelectedStyle(tcell.StyleDefault.Background(e.theme.Selection).Foreground(e.theme.Text))
+	p.SetPlaceholderStyle(tcell.StyleDefault.Background(e.theme.Background).Foreground(e.theme.Placeholder))
 
 	if e.goal.CompareStart(e.timeNow()) == 1 {
-		p.SetPlaceholder(futureGoalEditorPlaceholder)
+		p.SetPlaceholder(e.futurePlaceholder)
 	} else {
-		p.SetPlaceholder(goalEditorPlaceholder)
+		p.SetPlaceholder(e.placeholder)
 	}
 
 	p.SetChangedFunc(func() {
-		e.goal.Content = p.GetText()
-		if err := e.goalsRepository.Update(ctx, e.goal); err != nil {
-			log.Fatalf("failed to update goal: %s", err)
+		if !e.restoring {
+			e.history.Changed(ctx, e.goal.ID, e.goal.Content, p.GetText())
+		}
+		e.contentChanged(ctx, p.GetText())
+	})
+
+	p.SetFocusFunc(func() {
+		p.SetBorderColor(e.theme.FocusedBorder)
+		e.show(e.editor)
+		e.onFocus()
+	})
+	// called while the editor still has focus, so the mode is decided explicitly
+	p.SetBlurFunc(func() {
+		p.SetBorderColor(e.theme.Border)
+		e.autosaver.Blurred(ctx, e.goal.ID)
+		if e.isPreviewMode() {
+			e.showPreview()
 		}
 	})
+	p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey { return e.handleHotkeys(ctx, event) })
 
-	p.SetFocusFunc(e.onFocus)
-	p.SetInputCapture(e.handleHotkeys)
+	e.editor = p
+}
 
-	e.Primitive = p
+func (e *GoalEditor) contentChanged(ctx context.Context, content string) {
+	e.goal.Content = content
+	e.autosaver.Changed(ctx, e.goal)
+	// the conflict notice goes away once markers are removed
+	e.editor.SetTitle(e.title())
+	e.preview.SetTitle(e.title())
+}
+
+// restore sets content from the history, it isn't recorded as a new change
+func (e *GoalEditor) restore(ctx context.Context, move func(ctx context.Context, goalID string, current string) (string, bool)) {
+	content, ok := move(ctx, e.goal.ID, e.goal.Content)
+	if !ok {
+		return
+	}
+
+	// SetText calls the changed func, which updates the goal and saves it
+	e.restoring = true
+	e.editor.SetText(content, true)
+	e.restoring = false
+}
+
+func (e *GoalEditor) isPreviewMode() bool {
+	return e.isPreviewEnabled() != e.previewToggled
+}
+
+func (e *GoalEditor) show(p tview.Primitive) {
+	if e.Primitive.GetItemCount() == 1 && e.Primitive.GetItem(0) == p {
+		return
+	}
+
+	e.Primitive.Clear()
+	e.Primitive.AddItem(p, 0, 1, false)
+}
+
+func (e *GoalEditor) showPreview() {
+	e.preview.SetText(renderMarkdown(e.editor.GetText()))
+	e.preview.ScrollToBeginning()
+	e.show(e.preview)
+}
+
+// UpdateMode shows either the preview or the editor, the focused editor is always editable
+func (e *GoalEditor) UpdateMode() {
+	if e.isPreviewMode() && !e.editor.HasFocus() {
+		e.showPreview()
+	} else {
+		e.show(e.editor)
+	}
+}
+
+// TogglePreview switches the preview mode only for this editor
+func (e *GoalEditor) TogglePreview() {
+	e.previewToggled = !e.previewToggled
+	e.UpdateMode()
 }
 
 func (e *GoalEditor) handleList() bool {
-	_, start, end := e.Primitive.GetSelection()
+	_, start, end := e.editor.GetSelection()
 	if start != end {
 		return false
 	}
 
-	content := e.Primitive.GetText()
+	content := e.editor.GetText()
 	if content == "" {
 		return false
 	}
@@ -96,7 +235,7 @@ func (e *GoalEditor) handleList() bool {
 	lineEnd := utils.FindLineEnd(content, lineStart)
 	lineContent := content[lineStart:lineEnd]
 	if strings.TrimRight(lineContent, " \t") == "*" {
-		e.Primitive.Replace(lineStart, lineEnd+1, "")
+		e.editor.Replace(lineStart, lineEnd+1, "")
 		return true
 	}
 
@@ -104,38 +243,50 @@ func (e *GoalEditor) handleList() bool {
 	if !(start < len(content) && content[start] == ' ') {
 		toInsert += " "
 	}
-	e.Primitive.PasteHandler()(toInsert, nil)
+	e.editor.PasteHandler()(toInsert, nil)
 
 	return true
 }
 
-// manual ctrl+c / ctrl+v / ctrl + x
-func (e *GoalEditor) handleHotkeys(event *tcell.EventKey) *tcell.EventKey {
-	if event.Key() == tcell.KeyCtrlC {
-		log.Println("hotkey editor: ctrl c")
-		selected, _, _ := e.Primitive.GetSelection()
-		clipboard.Write(clipboard.FmtText, []byte(selected))
-		return nil
-	}
+func (e *GoalEditor) copy() {
+	selected, _, _ := e.editor.GetSelection()
+	clipboard.Write(clipboard.FmtText, []byte(selected))
+}
 
-	if event.Key() == tcell.KeyCtrlV {
-		log.Println("hotkey editor: ctrl v")
-		text := clipboard.Read(clipboard.FmtText)
-		e.Primitive.PasteHandler()(string(text), nil)
-		return nil
+func (e *GoalEditor) paste() {
+	text := clipboard.Read(clipboard.FmtText)
+	e.editor.PasteHandler()(string(text), nil)
+}
+
+func (e *GoalEditor) cut() {
+	selected, start, end := e.editor.GetSelection()
+	e.editor.Replace(start, end, "")
+	clipboard.Write(clipboard.FmtText, []byte(selected))
+}
+
+// commands has manual copy / paste / cut as tview clipboard is internal only, undo / redo as tview history is lost
+// with the editor
+func (e *GoalEditor) commands(ctx context.Context) []command {
+	return []command{
+		{ActionCopy, "Copy the selection", e.copy},
+		{ActionCut, "Cut the selection", e.cut},
+		{ActionPaste, "Paste", e.paste},
+		{ActionSelectAll, "Select all", func() { e.editor.Select(0, len(e.editor.GetText())) }},
+		{ActionUndo, "Undo, also changes from previous sessions", func() { e.restore(ctx, e.history.Undo) }},
+		{ActionRedo, "Redo", func() { e.restore(ctx, e.history.Redo) }},
+		{ActionUndoSession, "Undo all changes of the goal since the start", func() { e.restore(ctx, e.history.UndoSession) }},
+		{ActionToggleGoalPreview, "Toggle preview of the current goal", e.TogglePreview},
+		{ActionMoveTo, "Move the current lines to another goal", func() { e.onSend(e, true) }},
+		{ActionCopyTo, "Copy the current lines to another goal", func() { e.onSend(e, false) }},
 	}
+}
 
-	if event.Key() == tcell.KeyCtrlX {
-		log.Println("hotkey editor: ctrl x")
-		selected, start, end := e.Primitive.GetSelection()
-		e.Primitive.Replace(start, end, "")
-		clipboard.Write(clipboard.FmtText, []byte(selected))
+func (e *GoalEditor) handleHotkeys(ctx context.Context, event *tcell.EventKey) *tcell.EventKey {
+	if runCommand(e.keymap, event, e.commands(ctx)) {
 		return nil
 	}
 
-	if event.Key() == tcell.KeyCtrlA {
-		log.Println("hotkey editor: ctrl A")
-		e.Primitive.Select(0, len(e.Primitive.GetText()))
+	if e.vim != nil && e.handleVim(ctx, event) {
 		return nil
 	}
 
@@ -148,15 +299,135 @@ func (e *GoalEditor) handleHotkeys(event *tcell.EventKey) *tcell.EventKey {
 
 	if event.Key() == tcell.KeyEsc {
 		log.Println("hotkey editor: escape")
-		_, start, end := e.Primitive.GetSelection()
+		_, start, end := e.editor.GetSelection()
 		if start != end {
-			e.Primitive.Select(start, start)
+			e.editor.Select(start, start)
 		}
 	}
 
 	return event
 }
 
+// vimKey converts keys to runes understood by vim, false for keys the text area handles in any mode
+func vimKey(event *tcell.EventKey) (rune, bool) {
+	switch event.Key() {
+	case tcell.KeyRune:
+		return event.Rune(), event.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) == 0
+	case tcell.KeyEsc:
+		return vimKeyEsc, true
+	case tcell.KeyEnter:
+		return vimKeyEnter, true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		return vimKeyBackspace, true
+	case tcell.KeyCtrlR:
+		return vimKeyRedo, true
+	case tcell.KeyDelete:
+		return 'x', true
+	default:
+		return 0, false
+	}
+}
+
+// handleVim applies modal editing, the changed text is replaced minimally, so history and autosave see it as an edit
+func (e *GoalEditor) handleVim(ctx context.Context, event *tcell.EventKey) bool {
+	key, ok := vimKey(event)
+	if !ok {
+		// arrows and other navigation keys work as usual, the rest doesn't insert text outside the insert mode
+		return e.vim.mode != vimInsert && !isNavigationKey(event.Key())
+	}
+
+	before := e.editor.GetText()
+	_, start, end := e.editor.GetSelection()
+	buffer := e.vim.buffer(before, start, end)
+
+	switch e.vim.Key(buffer, key) {
+	case vimPass:
+		return false
+	case vimUndo:
+		log.Println("hotkey editor: vim undo")
+		e.restore(ctx, e.history.Undo)
+		return true
+	case vimRedo:
+		log.Println("hotkey editor: vim redo")
+		e.restore(ctx, e.history.Redo)
+		return true
+	}
+
+	e.replaceText(buffer.text)
+	e.editor.Select(e.vim.shownSelection(buffer))
+	e.editor.SetTitle(e.title())
+
+	return true
+}
+
+// replaceText replaces only the changed part, so the change is recorded like typing
+func (e *GoalEditor) replaceText(text string) {
+	before := e.editor.GetText()
+	if text == before {
+		return
+	}
+
+	prefix := 0
+	for prefix < len(before) && prefix < len(text) && before[prefix] == text[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(text)-prefix &&
+		before[len(before)-1-suffix] == text[len(text)-1-suffix] {
+		suffix++
+	}
+
+	e.editor.Replace(prefix, len(before)-suffix, text[prefix:len(text)-suffix])
+}
+
+// SetContent changes the text from outside of the editor, e.g. from the agenda, keeping the selection when possible
+func (e *GoalEditor) SetContent(content string) {
+	_, start, end := e.editor.GetSelection()
+	e.replaceText(content)
+	e.editor.Select(min(start, len(content)), min(end, len(content)))
+	if e.isPreviewMode() && !e.editor.HasFocus() {
+		e.showPreview()
+	}
+}
+
+// SelectLine moves the cursor to the start of the line counted from 0
+func (e *GoalEditor) SelectLine(line int) {
+	content := e.editor.GetText()
+	pos := 0
+	for range line {
+		next := strings.IndexByte(content[pos:], '\n')
+		if next == -1 {
+			break
+		}
+		pos += next + 1
+	}
+
+	e.editor.Select(pos, pos)
+}
+
+func isNavigationKey(key tcell.Key) bool {
+	switch key {
+	case tcell.KeyUp, tcell.KeyDown, tcell.KeyLeft, tcell.KeyRight, tcell.KeyHome, tcell.KeyEnd, tcell.KeyPgUp, tcell.KeyPgDn:
+		return true
+	default:
+		return false
+	}
+}
+
+func (e *GoalEditor) AcceptsText() bool {
+	return e.vim == nil || e.vim.mode == vimInsert
+}
+
+// ConsumesEscape is true when Esc switches the vim mode instead of counting towards the exit
+func (e *GoalEditor) ConsumesEscape() bool {
+	return e.vim != nil && e.vim.consumesEscape()
+}
+
 func (e *GoalEditor) Focus() {
-	e.app.SetFocus(e.Primitive)
+	e.app.SetFocus(e.editor)
+}
+
+func (e *GoalEditor) PrimitiveInFocus() tview.Primitive {
+	return e.editor
 }
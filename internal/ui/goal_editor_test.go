@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"github.com/nvbn/termonizer/internal/config"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+	"testing"
+	"time"
+)
+
+func TestGoalEditor_UndoRedo(t *testing.T) {
+	ctx := t.Context()
+	clock := &historyClock{now: time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC)}
+	saved := &savedGoals{}
+	currentTheme, err := theme.NewThemes().Get("dark")
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	e := NewGoalEditor(ctx, GoalEditorProps{
+		app:              tview.NewApplication(),
+		timeNow:          clock.timeNow,
+		theme:            currentTheme,
+		autosaver:        newAutosaver(config.AutosaveImmediate, time.Second, saved.save),
+		history:          newTestHistory(clock, "a"),
+		goal:             model.Goal{ID: "goal", Period: model.Day, Start: clock.now, Content: "a"},
+		onFocus:          func() {},
+		isPreviewEnabled: func() bool { return false },
+	})
+
+	for _, content := range []string{"ab", "abc"} {
+		clock.tick()
+		e.editor.SetText(content, true)
+	}
+
+	inputsExpecteds := []struct {
+		action   func()
+		expected string
+	}{
+		{func() { e.restore(ctx, e.history.Undo) }, "ab"},
+		{func() { e.restore(ctx, e.history.Undo) }, "a"},
+		{func() { e.restore(ctx, e.history.Redo) }, "ab"},
+		{func() { e.restore(ctx, e.history.Redo) }, "abc"},
+		{func() { e.restore(ctx, e.history.Redo) }, "abc"},
+	}
+
+	for n, inputExpected := range inputsExpecteds {
+		inputExpected.action()
+
+		if e.editor.GetText() != inputExpected.expected {
+			t.Errorf("step %d: expected %q, got %q", n, inputExpected.expected, e.editor.GetText())
+		}
+
+		if e.goal.Content != inputExpected.expected {
+			t.Errorf("step %d: expected the goal to be %q, got %q", n, inputExpected.expected, e.goal.Content)
+		}
+	}
+
+	if last := saved.goals[len(saved.goals)-1]; last.Content != "abc" {
+		t.Errorf("expected restored content to be saved, got %q", last.Content)
+	}
+}
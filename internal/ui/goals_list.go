@@ -4,7 +4,9 @@ import (
 	"context"
 	"github.com/gdamore/tcell/v2"
 	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/nvbn/termonizer/internal/config"
 	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
 	"github.com/rivo/tview"
 	"log"
 	"time"
@@ -15,10 +17,19 @@ const editorsCacheSize = 256
 type GoalsListProps struct {
 	app                *tview.Application
 	timeNow            func() time.Time
+	theme              theme.Theme
+	keymap             *Keymap
+	placeholder        string
+	futurePlaceholder  string
+	autosaver          *autosaver
+	history            *history
+	editingMode        config.EditingMode
 	period             model.Period
 	goalsRepository    goalsRepository
 	settingsRepository settingsRepository
 	onFocus            func()
+	onSend             func(editor *GoalEditor, move bool)
+	isPreviewEnabled   func() bool
 }
 
 type GoalsList struct {
@@ -56,10 +67,34 @@ func (l *GoalsList) EditorInFocus() *GoalEditor {
 	return l.inView[l.currentFocus]
 }
 
+// Editor returns the rendered editor of the goal, it has to be used for changing goals with it
+func (l *GoalsList) Editor(id string) (*GoalEditor, bool) {
+	return l.editorsCache.Peek(id)
+}
+
+// EditorAt returns the visible editor of the goal containing the date, goals that aren't stored yet get new ids on
+// every read, so the rendered goal has to be changed
+func (l *GoalsList) EditorAt(dt time.Time) (*GoalEditor, bool) {
+	for _, editor := range l.inView {
+		if editor.goal.CompareStart(dt) == 0 {
+			return editor, true
+		}
+	}
+
+	return nil, false
+}
+
 func (l *GoalsList) Focus() {
 	l.EditorInFocus().Focus()
 }
 
+// UpdatePreview re-applies the preview mode to the visible editors
+func (l *GoalsList) UpdatePreview() {
+	for _, editor := range l.inView {
+		editor.UpdateMode()
+	}
+}
+
 func (l *GoalsList) ScrollFuture(ctx context.Context) {
 	if l.offset >= 1 {
 		l.offset -= 1
@@ -88,6 +123,47 @@ func (l *GoalsList) ScrollPast(ctx context.Context) {
 	l.render(ctx)
 }
 
+// show scrolls to the goal by its position among all goals of the period and focuses it
+func (l *GoalsList) show(ctx context.Context, position int, total int) {
+	l.offset = max(min(position, total-l.amountToShow()), 0)
+	l.currentFocus = position - l.offset
+	l.render(ctx)
+}
+
+// ShowDate focuses the goal of the period containing the date, or the closest earlier one when there's no goal
+func (l *GoalsList) ShowDate(ctx context.Context, dt time.Time) bool {
+	goals, err := l.goalsRepository.FindForPeriod(ctx, l.period)
+	if err != nil {
+		log.Fatalf("failed to find goals: %v", err)
+	}
+
+	for n, goal := range goals {
+		if goal.CompareStart(dt) <= 0 {
+			l.show(ctx, n, len(goals))
+			return true
+		}
+	}
+
+	return false
+}
+
+// ShowGoal focuses the goal with the id, false when it's not in the period
+func (l *GoalsList) ShowGoal(ctx context.Context, id string) bool {
+	goals, err := l.goalsRepository.FindForPeriod(ctx, l.period)
+	if err != nil {
+		log.Fatalf("failed to find goals: %v", err)
+	}
+
+	for n, goal := range goals {
+		if goal.ID == id {
+			l.show(ctx, n, len(goals))
+			return true
+		}
+	}
+
+	return false
+}
+
 func (l *GoalsList) focusFuture(ctx context.Context) {
 	if l.currentFocus == 0 {
 		l.ScrollFuture(ctx)
@@ -106,6 +182,20 @@ func (l *GoalsList) focusPast(ctx context.Context) {
 	}
 }
 
+// deleteGoal moves the focused goal to the trash, pending changes are saved first so the trash has the latest content
+func (l *GoalsList) deleteGoal(ctx context.Context) {
+	goal := l.EditorInFocus().goal
+	l.autosaver.flushGoal(ctx, goal.ID)
+
+	if err := l.goalsRepository.Delete(ctx, goal); err != nil {
+		log.Fatalf("failed to delete goal: %v", err)
+	}
+
+	l.editorsCache.Remove(goal.ID)
+	l.currentFocus = min(l.currentFocus, len(l.getVisibleGoals(ctx))-1)
+	l.render(ctx)
+}
+
 func (l *GoalsList) getVisibleGoals(ctx context.Context) []model.Goal {
 	goals, err := l.goalsRepository.FindForPeriod(ctx, l.period)
 	if err != nil {
@@ -172,36 +262,19 @@ func (l *GoalsList) setAmountToShow(ctx context.Context, amount int) error {
 	return l.settingsRepository.SetAmountForPeriod(ctx, l.period, amount)
 }
 
-func (l *GoalsList) handleHotkeys(ctx context.Context, event *tcell.EventKey) *tcell.EventKey {
-	if event.Key() == tcell.KeyUp && event.Modifiers()&tcell.ModShift != 0 && event.Modifiers()&tcell.ModAlt != 0 {
-		log.Println("hotkey: option shift up")
-		l.ScrollNow(ctx)
-		return nil
-	}
-
-	if event.Key() == tcell.KeyUp && event.Modifiers()&tcell.ModAlt != 0 {
-		log.Println("hotkey: option up")
-		l.focusFuture(ctx)
-		return nil
-	}
-
-	if event.Key() == tcell.KeyDown && event.Modifiers()&tcell.ModAlt != 0 {
-		log.Println("hotkey: option down")
-		l.focusPast(ctx)
-		return nil
-	}
-
-	// option + =
-	if event.Key() == tcell.KeyRune && event.Rune() == '≠' {
-		log.Println("hotkey: option +")
-		l.zoomIn(ctx)
-		return nil
+func (l *GoalsList) commands(ctx context.Context) []command {
+	return []command{
+		{ActionFocusNow, "Focus the current goal", func() { l.ScrollNow(ctx) }},
+		{ActionFocusFuture, "Focus the future goal or the goal above", func() { l.focusFuture(ctx) }},
+		{ActionFocusPast, "Focus the past goal or the goal below", func() { l.focusPast(ctx) }},
+		{ActionZoomIn, "Zoom in, decrease the amount of visible goals", func() { l.zoomIn(ctx) }},
+		{ActionZoomOut, "Zoom out, increase the amount of visible goals", func() { l.zoomOut(ctx) }},
+		{ActionDeleteGoal, "Move the goal to the trash", func() { l.deleteGoal(ctx) }},
 	}
+}
 
-	// option + -
-	if event.Key() == tcell.KeyRune && event.Rune() == '–' {
-		log.Println("hotkey: option -")
-		l.zoomOut(ctx)
+func (l *GoalsList) handleHotkeys(ctx context.Context, event *tcell.EventKey) *tcell.EventKey {
+	if runCommand(l.keymap, event, l.commands(ctx)) {
 		return nil
 	}
 
@@ -224,10 +297,19 @@ func (l *GoalsList) render(ctx context.Context) {
 			editor = existingEditor
 		} else {
 			editor = NewGoalEditor(ctx, GoalEditorProps{
-				app:             l.app,
-				timeNow:         l.timeNow,
-				goalsRepository: l.goalsRepository,
-				goal:            goal,
+				app:               l.app,
+				timeNow:           l.timeNow,
+				theme:             l.theme,
+				keymap:            l.keymap,
+				placeholder:       l.placeholder,
+				futurePlaceholder: l.futurePlaceholder,
+				autosaver:         l.autosaver,
+				history:           l.history,
+				editingMode:       l.editingMode,
+				goalsRepository:   l.goalsRepository,
+				goal:              goal,
+				isPreviewEnabled:  l.isPreviewEnabled,
+				onSend:            l.onSend,
 				onFocus: func() {
 					// could be called during the first rendering
 					if pos, ok := l.idToPosition[goal.ID]; ok {
@@ -240,6 +322,8 @@ func (l *GoalsList) render(ctx context.Context) {
 			l.editorsCache.Add(goal.ID, editor)
 		}
 
+		editor.UpdateMode()
+
 		nextInView = append(nextInView, editor)
 		l.Primitive.AddItem(editor.Primitive, 0, 1, false)
 
@@ -248,7 +332,7 @@ func (l *GoalsList) render(ctx context.Context) {
 		}
 	}
 
-	l.app.SetFocus(nextInView[nextIdToPosition[idToFocusNow]].Primitive)
+	l.app.SetFocus(nextInView[nextIdToPosition[idToFocusNow]].PrimitiveInFocus())
 
 	l.inView = nextInView
 	l.idToPosition = nextIdToPosition
@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+	"strings"
+	"unicode/utf8"
+)
+
+// keys that macos terminals send for option + key with the us layout
+var optionRunes = map[string]string{
+	"∏": "⇧⌥P",
+	"π": "⌥P",
+	"≠": "⌥=",
+	"–": "⌥-",
+	"®": "⌥R",
+	"∂": "⌥D",
+	"Ω": "⌥Z",
+	"ç": "⌥C",
+	"¬": "⌥L",
+	"å": "⌥A",
+	"µ": "⌥M",
+	"Â": "⇧⌥M",
+}
+
+var keySymbols = map[string]string{
+	"shift": "⇧",
+	"alt":   "⌥",
+	"meta":  "⌘",
+	"ctrl":  "⌃",
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+}
+
+// formatKey shows a key from the keymap like macos menus, e.g. "Shift+Alt+Left" is "⇧⌥←"
+func formatKey(key string) string {
+	normalized, err := normalizeKey(key)
+	if err != nil {
+		return key
+	}
+
+	var formatted strings.Builder
+	parts := strings.Split(normalized, "+")
+	for _, modifier := range parts[:len(parts)-1] {
+		formatted.WriteString(keySymbols[modifier])
+	}
+
+	name := parts[len(parts)-1]
+	if r, ok := strings.CutPrefix(name, "Rune["); ok {
+		r = strings.TrimSuffix(r, "]")
+		if option, ok := optionRunes[r]; ok && formatted.Len() == 0 {
+			return option
+		}
+		formatted.WriteString(r)
+	} else if symbol, ok := keySymbols[name]; ok {
+		formatted.WriteString(symbol)
+	} else {
+		formatted.WriteString(strings.ToUpper(name[:1]) + name[1:])
+	}
+
+	return formatted.String()
+}
+
+func formatKeys(keys []string) string {
+	formatted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		formatted = append(formatted, formatKey(key))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// helpGroup is commands of a component with keys handled outside of the keymap
+type helpGroup struct {
+	title    string
+	commands []command
+	extra    [][2]string
+}
+
+// helpGroups lists commands registered by components, so the help doesn't drift from the code
+func helpGroups(ctx context.Context, cli *CLI, list *GoalsList, editor *GoalEditor) []helpGroup {
+	groups := []helpGroup{
+		{"App", cli.commands(ctx), [][2]string{{"Esc Esc", "Exit"}, {"?", "Show this help outside of the text input"}}},
+		{"Goals", list.commands(ctx), nil},
+		{"Text editing", editor.commands(ctx), [][2]string{{"Enter", "Continue the list"}, {"Esc", "Remove the selection"}}},
+	}
+
+	if editor != nil && editor.vim != nil {
+		groups = append(groups, helpGroup{"Vim editing mode", nil, vimHelp})
+	}
+
+	return groups
+}
+
+// renderHelp aligns descriptions in a column, actions without keys are only in the command palette
+func renderHelp(keymap *Keymap, groups []helpGroup) string {
+	lines := make([][][2]string, 0, len(groups))
+	width := 0
+	for _, group := range groups {
+		groupLines := make([][2]string, 0, len(group.commands)+len(group.extra))
+		for _, c := range group.commands {
+			keys := formatKeys(keymap.Keys(c.action))
+			if keys == "" {
+				keys = "palette"
+			}
+			groupLines = append(groupLines, [2]string{keys, c.description})
+		}
+		groupLines = append(groupLines, group.extra...)
+
+		for _, line := range groupLines {
+			width = max(width, utf8.RuneCountInString(line[0]))
+		}
+		lines = append(lines, groupLines)
+	}
+
+	var out strings.Builder
+	for n, group := range groups {
+		if n > 0 {
+			out.WriteString("\n")
+		}
+
+		fmt.Fprintf(&out, "%s:\n", group.title)
+		for _, line := range lines[n] {
+			fmt.Fprintf(&out, "  %-*s  %s\n", width, line[0], line[1])
+		}
+	}
+
+	return out.String()
+}
+
+// Help returns hotkeys of all components for the usage, handlers aren't called, so components are empty
+func Help(keymap *Keymap) string {
+	return renderHelp(keymap, helpGroups(context.Background(), &CLI{}, &GoalsList{}, &GoalEditor{}))
+}
+
+type HelpDialogProps struct {
+	app     *tview.Application
+	theme   theme.Theme
+	keymap  *Keymap
+	content string
+	onClose func()
+}
+
+// HelpDialog shows hotkeys of the focused components
+type HelpDialog struct {
+	HelpDialogProps
+
+	Primitive tview.Primitive
+
+	view *tview.TextView
+}
+
+func NewHelpDialog(props HelpDialogProps) *HelpDialog {
+	d := &HelpDialog{HelpDialogProps: props}
+	d.initPrimitive()
+	return d
+}
+
+func (d *HelpDialog) initPrimitive() {
+	d.view = tview.NewTextView().SetText(d.content).SetWrap(false)
+	d.view.SetTextColor(d.theme.Text)
+	d.view.SetBorder(true).SetTitle("Help, Esc to close").SetTitleColor(d.theme.PanelTitle).SetBorderColor(d.theme.FocusedBorder)
+	d.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if d.keymap.Is(event, ActionHelp) || event.Key() == tcell.KeyEsc ||
+			(event.Key() == tcell.KeyRune && (event.Rune() == '?' || event.Rune() == 'q')) {
+			d.onClose()
+			return nil
+		}
+
+		return event
+	})
+
+	d.Primitive = centered(d.view, 90, 40)
+}
+
+func (d *HelpDialog) Focus() {
+	d.app.SetFocus(d.view)
+}
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestFormatKey(t *testing.T) {
+	inputsExpecteds := map[string]string{
+		"Shift+Alt+Left": "⇧⌥←",
+		"Alt+Up":         "⌥↑",
+		"Ctrl+Z":         "⌃Z",
+		"Rune[π]":        "⌥P",
+		"Alt+Rune[h]":    "⌥h",
+		"Rune[x]":        "x",
+		"F1":             "F1",
+		"Enter":          "Enter",
+	}
+
+	for key, expected := range inputsExpecteds {
+		if actual := formatKey(key); actual != expected {
+			t.Errorf("%s: expected %s, got %s", key, expected, actual)
+		}
+	}
+}
+
+// every action should be registered by a component, otherwise it's neither in the help nor in the palette
+func TestHelpGroups_AllActions(t *testing.T) {
+	registered := make(map[Action]bool)
+	for _, group := range helpGroups(context.Background(), &CLI{}, &GoalsList{}, &GoalEditor{}) {
+		for _, c := range group.commands {
+			if registered[c.action] {
+				t.Errorf("%s is registered twice", c.action)
+			}
+			registered[c.action] = true
+		}
+	}
+
+	for action := range defaultKeymap {
+		if !registered[action] {
+			t.Errorf("%s isn't registered", action)
+		}
+	}
+}
+
+func TestHelp(t *testing.T) {
+	k, err := NewKeymap(map[string][]string{string(ActionFocusLeft): {"Alt+Rune[h]"}})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	help := Help(k)
+	for _, expected := range []string{"App:\n", "Goals:\n", "Text editing:\n", "⌥h", "palette"} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("expected %q in:\n%s", expected, help)
+		}
+	}
+
+	if strings.Contains(help, "⇧⌥←") {
+		t.Errorf("expected the overridden key instead of the default in:\n%s", help)
+	}
+}
+
+func TestHelpGroups_Vim(t *testing.T) {
+	hasVim := func(editor *GoalEditor) bool {
+		for _, group := range helpGroups(context.Background(), &CLI{}, &GoalsList{}, editor) {
+			if group.title == "Vim editing mode" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasVim(&GoalEditor{}) {
+		t.Error("expected no vim keys without the vim mode")
+	}
+
+	if !hasVim(&GoalEditor{vim: newVim(nil, nil)}) {
+		t.Error("expected vim keys in the vim mode")
+	}
+}
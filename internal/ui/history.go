@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/model"
+	"log"
+	"time"
+)
+
+// changes typed in quick succession are undone as a single step
+const historyCoalesceInterval = time.Second
+
+// goalHistory is the undo stack of a goal, states after position could be redone
+type goalHistory struct {
+	states       []string
+	position     int
+	sessionStart int
+	lastChange   time.Time
+}
+
+// history keeps undo stacks of goals outside of editors, so they survive when an editor is evicted from the cache,
+// stacks start from stored revisions, so changes from previous sessions could be undone too
+type history struct {
+	timeNow       func() time.Time
+	readRevisions func(ctx context.Context, goalID string) ([]model.Revision, error)
+
+	goals map[string]*goalHistory
+}
+
+func newHistory(timeNow func() time.Time, readRevisions func(ctx context.Context, goalID string) ([]model.Revision, error)) *history {
+	return &history{
+		timeNow:       timeNow,
+		readRevisions: readRevisions,
+		goals:         make(map[string]*goalHistory),
+	}
+}
+
+func (h *history) load(ctx context.Context, goalID string, current string) *goalHistory {
+	if goal, ok := h.goals[goalID]; ok {
+		return goal
+	}
+
+	revisions, err := h.readRevisions(ctx, goalID)
+	if err != nil {
+		log.Fatalf("failed to read revisions: %v", err)
+	}
+
+	goal := &goalHistory{states: make([]string, 0, len(revisions)+1)}
+	for _, revision := range revisions {
+		if len(goal.states) == 0 || goal.states[len(goal.states)-1] != revision.Content {
+			goal.states = append(goal.states, revision.Content)
+		}
+	}
+
+	// not saved yet or changed outside
+	if len(goal.states) == 0 || goal.states[len(goal.states)-1] != current {
+		goal.states = append(goal.states, current)
+	}
+
+	goal.position = len(goal.states) - 1
+	goal.sessionStart = goal.position
+	h.goals[goalID] = goal
+
+	return goal
+}
+
+// Changed records the new content, states that could be redone are dropped
+func (h *history) Changed(ctx context.Context, goalID string, before string, after string) {
+	goal := h.load(ctx, goalID, before)
+	goal.states = goal.states[:goal.position+1]
+
+	now := h.timeNow()
+	if goal.position > goal.sessionStart && now.Sub(goal.lastChange) < historyCoalesceInterval {
+		goal.states[goal.position] = after
+	} else {
+		goal.states = append(goal.states, after)
+		goal.position += 1
+	}
+
+	goal.lastChange = now
+}
+
+func (h *history) move(goal *goalHistory, position int) (string, bool) {
+	if position < 0 || position >= len(goal.states) || position == goal.position {
+		return "", false
+	}
+
+	goal.position = position
+	// the next change after undo or redo is a new step
+	goal.lastChange = time.Time{}
+
+	return goal.states[position], true
+}
+
+// Undo returns the previous content, false when there's nothing to undo
+func (h *history) Undo(ctx context.Context, goalID string, current string) (string, bool) {
+	goal := h.load(ctx, goalID, current)
+	return h.move(goal, goal.position-1)
+}
+
+func (h *history) Redo(ctx context.Context, goalID string, current string) (string, bool) {
+	goal := h.load(ctx, goalID, current)
+	return h.move(goal, goal.position+1)
+}
+
+// UndoSession returns the content before the first change since the start, the changes could be redone
+func (h *history) UndoSession(ctx context.Context, goalID string, current string) (string, bool) {
+	goal := h.load(ctx, goalID, current)
+	return h.move(goal, goal.sessionStart)
+}
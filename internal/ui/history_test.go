@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/model"
+	"testing"
+	"time"
+)
+
+type historyClock struct {
+	now time.Time
+}
+
+func (c *historyClock) timeNow() time.Time {
+	return c.now
+}
+
+func (c *historyClock) tick() {
+	c.now = c.now.Add(historyCoalesceInterval)
+}
+
+func newTestHistory(clock *historyClock, revisions ...string) *history {
+	return newHistory(clock.timeNow, func(ctx context.Context, goalID string) ([]model.Revision, error) {
+		stored := make([]model.Revision, 0, len(revisions))
+		for _, content := range revisions {
+			stored = append(stored, model.Revision{GoalID: goalID, Content: content})
+		}
+		return stored, nil
+	})
+}
+
+func TestHistory_UndoRedo(t *testing.T) {
+	ctx := t.Context()
+	clock := &historyClock{now: time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC)}
+	h := newTestHistory(clock, "a", "ab", "ab")
+
+	// typed quickly, so a single step
+	h.Changed(ctx, "goal", "ab", "abc")
+	h.Changed(ctx, "goal", "abc", "abcd")
+	clock.tick()
+	h.Changed(ctx, "goal", "abcd", "abcde")
+
+	inputsExpecteds := []struct {
+		move     func(ctx context.Context, goalID string, current string) (string, bool)
+		expected string
+		ok       bool
+	}{
+		{h.Undo, "abcd", true},
+		{h.Undo, "ab", true},
+		// from the previous session
+		{h.Undo, "a", true},
+		{h.Undo, "", false},
+		{h.Redo, "ab", true},
+		{h.Redo, "abcd", true},
+		{h.Redo, "abcde", true},
+		{h.Redo, "", false},
+		{h.UndoSession, "ab", true},
+		{h.UndoSession, "", false},
+	}
+
+	for n, inputsExpected := range inputsExpecteds {
+		content, ok := inputsExpected.move(ctx, "goal", "")
+		if content != inputsExpected.expected || ok != inputsExpected.ok {
+			t.Errorf("step %d: expected %q %v, got %q %v", n, inputsExpected.expected, inputsExpected.ok, content, ok)
+		}
+	}
+}
+
+func TestHistory_ChangeAfterUndo(t *testing.T) {
+	ctx := t.Context()
+	clock := &historyClock{now: time.Date(2024, 12, 10, 0, 0, 0, 0, time.UTC)}
+	h := newTestHistory(clock)
+
+	h.Changed(ctx, "goal", "", "a")
+	clock.tick()
+	h.Changed(ctx, "goal", "a", "ab")
+
+	if content, _ := h.Undo(ctx, "goal", "ab"); content != "a" {
+		t.Errorf("expected a, got %q", content)
+	}
+
+	// right after undo, but still a new step
+	h.Changed(ctx, "goal", "a", "ac")
+
+	if _, ok := h.Redo(ctx, "goal", "ac"); ok {
+		t.Error("expected nothing to redo")
+	}
+
+	if content, _ := h.Undo(ctx, "goal", "ac"); content != "a" {
+		t.Errorf("expected a, got %q", content)
+	}
+}
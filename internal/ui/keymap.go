@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+type Action string
+
+const (
+	ActionFocusLeft         Action = "focus_left"
+	ActionFocusRight        Action = "focus_right"
+	ActionTogglePreview     Action = "toggle_preview"
+	ActionFocusNow          Action = "focus_now"
+	ActionFocusFuture       Action = "focus_future"
+	ActionFocusPast         Action = "focus_past"
+	ActionZoomIn            Action = "zoom_in"
+	ActionZoomOut           Action = "zoom_out"
+	ActionCopy              Action = "copy"
+	ActionCut               Action = "cut"
+	ActionPaste             Action = "paste"
+	ActionSelectAll         Action = "select_all"
+	ActionToggleGoalPreview Action = "toggle_goal_preview"
+	ActionRecurring         Action = "recurring"
+	ActionDeleteGoal        Action = "delete_goal"
+	ActionUndo              Action = "undo"
+	ActionRedo              Action = "redo"
+	ActionUndoSession       Action = "undo_session"
+	ActionPalette           Action = "palette"
+	ActionSearch            Action = "search"
+	ActionGoToDate          Action = "go_to_date"
+	ActionExport            Action = "export"
+	ActionHelp              Action = "help"
+	ActionZen               Action = "zen"
+	ActionCompare           Action = "compare"
+	ActionLayout            Action = "layout"
+	ActionAgenda            Action = "agenda"
+	ActionMoveTo            Action = "move_to"
+	ActionCopyTo            Action = "copy_to"
+)
+
+// option + rune bindings are what macos terminals send for option + key
+var defaultKeymap = map[Action][]string{
+	ActionFocusLeft:         {"Shift+Alt+Left"},
+	ActionFocusRight:        {"Shift+Alt+Right"},
+	ActionTogglePreview:     {"Rune[∏]"},
+	ActionFocusNow:          {"Shift+Alt+Up"},
+	ActionFocusFuture:       {"Alt+Up"},
+	ActionFocusPast:         {"Alt+Down"},
+	ActionZoomIn:            {"Rune[≠]"},
+	ActionZoomOut:           {"Rune[–]"},
+	ActionCopy:              {"Ctrl+C"},
+	ActionCut:               {"Ctrl+X"},
+	ActionPaste:             {"Ctrl+V"},
+	ActionSelectAll:         {"Ctrl+A"},
+	ActionToggleGoalPreview: {"Rune[π]"},
+	ActionRecurring:         {"Rune[®]"},
+	ActionDeleteGoal:        {"Rune[∂]"},
+	ActionUndo:              {"Ctrl+Z"},
+	ActionRedo:              {"Ctrl+Y"},
+	ActionUndoSession:       {"Rune[Ω]"},
+	ActionPalette:           {"Ctrl+P"},
+	ActionSearch:            {"Ctrl+F"},
+	ActionGoToDate:          {"Ctrl+G"},
+	ActionHelp:              {"F1"},
+	ActionZen:               {"F11"},
+	ActionCompare:           {"Rune[ç]"},
+	ActionLayout:            {"Rune[¬]"},
+	ActionAgenda:            {"Rune[å]"},
+	ActionMoveTo:            {"Rune[µ]"},
+	ActionCopyTo:            {"Rune[Â]"},
+	// only in the command palette
+	ActionExport: {},
+}
+
+var modifiersOrder = []string{"shift", "alt", "meta", "ctrl"}
+
+// Keymap binds actions to keys named like tcell.EventKey.Name, e.g. "Shift+Alt+Left" or "Rune[≠]"
+type Keymap struct {
+	actionToKeys map[Action][]string
+	keyToAction  map[string]Action
+}
+
+func NewKeymap(overrides map[string][]string) (*Keymap, error) {
+	k := &Keymap{
+		actionToKeys: maps.Clone(defaultKeymap),
+		keyToAction:  make(map[string]Action),
+	}
+
+	for name, keys := range overrides {
+		action := Action(name)
+		if _, ok := defaultKeymap[action]; !ok {
+			return nil, fmt.Errorf("unknown action %s", name)
+		}
+
+		k.actionToKeys[action] = keys
+	}
+
+	for _, action := range slices.Sorted(maps.Keys(k.actionToKeys)) {
+		for _, key := range k.actionToKeys[action] {
+			normalized, err := normalizeKey(key)
+			if err != nil {
+				return nil, fmt.Errorf("action %s: %w", action, err)
+			}
+
+			if bound, ok := k.keyToAction[normalized]; ok {
+				return nil, fmt.Errorf("key %s is bound to both %s and %s", key, bound, action)
+			}
+
+			k.keyToAction[normalized] = action
+		}
+	}
+
+	return k, nil
+}
+
+// normalizeKey makes names comparable, modifiers order and case don't matter
+func normalizeKey(name string) (string, error) {
+	var modifiers []string
+	var key string
+	if idx := strings.Index(name, "Rune["); idx != -1 {
+		key = name[idx:]
+		if !strings.HasSuffix(key, "]") || utf8.RuneCountInString(key) != len("Rune[]")+1 {
+			return "", fmt.Errorf("invalid rune key %s", name)
+		}
+		modifiers = strings.FieldsFunc(name[:idx], func(r rune) bool { return r == '+' })
+	} else {
+		parts := strings.Split(name, "+")
+		key = strings.ToLower(parts[len(parts)-1])
+		modifiers = parts[:len(parts)-1]
+	}
+
+	if after, ok := strings.CutPrefix(key, "ctrl-"); ok {
+		key = after
+		modifiers = append(modifiers, "ctrl")
+	}
+
+	for _, modifier := range modifiers {
+		if !slices.Contains(modifiersOrder, strings.ToLower(modifier)) {
+			return "", fmt.Errorf("unknown modifier %s in key %s", modifier, name)
+		}
+	}
+
+	normalizedModifiers := make([]string, 0, len(modifiers))
+	for _, modifier := range modifiersOrder {
+		if slices.ContainsFunc(modifiers, func(m string) bool { return strings.EqualFold(m, modifier) }) {
+			normalizedModifiers = append(normalizedModifiers, modifier)
+		}
+	}
+
+	if !strings.HasPrefix(key, "Rune[") && !isKnownKey(key) {
+		return "", fmt.Errorf("unknown key %s", name)
+	}
+
+	return strings.Join(append(normalizedModifiers, key), "+"), nil
+}
+
+func isKnownKey(key string) bool {
+	if len(key) == 1 {
+		return true // ctrl + letter
+	}
+
+	for _, name := range tcell.KeyNames {
+		name = strings.ToLower(name)
+		if name == key || strings.TrimPrefix(name, "ctrl-") == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Is checks that the event is bound to the action
+func (k *Keymap) Is(event *tcell.EventKey, action Action) bool {
+	normalized, err := normalizeKey(event.Name())
+	if err != nil {
+		return false
+	}
+
+	bound, ok := k.keyToAction[normalized]
+	return ok && bound == action
+}
+
+// Keys returns keys bound to the action
+func (k *Keymap) Keys(action Action) []string {
+	return k.actionToKeys[action]
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"testing"
+)
+
+func TestKeymap_Is(t *testing.T) {
+	k, err := NewKeymap(map[string][]string{
+		string(ActionFocusLeft): {"alt+shift+left", "Alt+Rune[h]"},
+	})
+	if err != nil {
+		t.Fatal("unexpected error:", err)
+	}
+
+	type testData struct {
+		event    *tcell.EventKey
+		action   Action
+		expected bool
+	}
+
+	inputsExpecteds := map[string]testData{
+		"overridden":          {tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModShift|tcell.ModAlt), ActionFocusLeft, true},
+		"overridden rune":     {tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModAlt), ActionFocusLeft, true},
+		"rune without alt":    {tcell.NewEventKey(tcell.KeyRune, 'h', tcell.ModNone), ActionFocusLeft, false},
+		"default":             {tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModAlt), ActionFocusFuture, true},
+		"default rune":        {tcell.NewEventKey(tcell.KeyRune, '≠', tcell.ModNone), ActionZoomIn, true},
+		"ctrl without mod":    {tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModNone), ActionCopy, true},
+		"ctrl with mod":       {tcell.NewEventKey(tcell.KeyCtrlC, 0, tcell.ModCtrl), ActionCopy, true},
+		"other action":        {tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModAlt), ActionFocusPast, false},
+		"missing modifier":    {tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone), ActionFocusFuture, false},
+		"additional modifier": {tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModAlt|tcell.ModCtrl), ActionFocusFuture, false},
+	}
+
+	for name, inputExpected := range inputsExpecteds {
+		t.Run(name, func(t *testing.T) {
+			if actual := k.Is(inputExpected.event, inputExpected.action); actual != inputExpected.expected {
+				t.Errorf("got %v, want %v for %s", actual, inputExpected.expected, inputExpected.event.Name())
+			}
+		})
+	}
+}
+
+func TestNewKeymap_Invalid(t *testing.T) {
+	invalid := map[string]map[string][]string{
+		"unknown action":   {"unknown": {"Alt+Up"}},
+		"unknown key":      {string(ActionFocusLeft): {"Alt+Nope"}},
+		"unknown modifier": {string(ActionFocusLeft): {"Hyper+Left"}},
+		"conflict":         {string(ActionFocusLeft): {"Alt+Up"}},
+	}
+
+	for name, overrides := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewKeymap(overrides); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
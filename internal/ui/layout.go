@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"slices"
+	"strings"
+)
+
+// narrower terminals show one period at a time with the auto layout
+const layoutColumnsMinWidth = 120
+
+// effectiveLayout resolves the auto layout for the terminal width
+func effectiveLayout(layout model.Layout, width int) model.Layout {
+	if layout != model.LayoutAuto {
+		return layout
+	}
+
+	if width < layoutColumnsMinWidth {
+		return model.LayoutTabs
+	}
+
+	return model.LayoutColumns
+}
+
+// nextLayout cycles through all layouts
+func nextLayout(layout model.Layout) model.Layout {
+	position := slices.Index(model.Layouts, layout)
+	return model.Layouts[(position+1)%len(model.Layouts)]
+}
+
+// tabsText renders periods as clickable regions named by their positions
+func tabsText(periods []model.Period) string {
+	tabs := make([]string, 0, len(periods))
+	for n, period := range periods {
+		tabs = append(tabs, fmt.Sprintf(`["%d"] %s [""]`, n, model.PeriodName(period)))
+	}
+
+	return strings.Join(tabs, "|")
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"github.com/nvbn/termonizer/internal/model"
+	"testing"
+)
+
+func TestEffectiveLayout(t *testing.T) {
+	inputsExpecteds := []struct {
+		layout   model.Layout
+		width    int
+		expected model.Layout
+	}{
+		{model.LayoutAuto, 200, model.LayoutColumns},
+		{model.LayoutAuto, layoutColumnsMinWidth, model.LayoutColumns},
+		{model.LayoutAuto, layoutColumnsMinWidth - 1, model.LayoutTabs},
+		{model.LayoutColumns, 80, model.LayoutColumns},
+		{model.LayoutRows, 200, model.LayoutRows},
+		{model.LayoutTabs, 200, model.LayoutTabs},
+	}
+
+	for _, tt := range inputsExpecteds {
+		if actual := effectiveLayout(tt.layout, tt.width); actual != tt.expected {
+			t.Errorf("%s with %d: expected %s, got %s", tt.layout, tt.width, tt.expected, actual)
+		}
+	}
+}
+
+func TestNextLayout(t *testing.T) {
+	layout := model.LayoutAuto
+	seen := make([]model.Layout, 0, len(model.Layouts))
+	for range model.Layouts {
+		layout = nextLayout(layout)
+		seen = append(seen, layout)
+	}
+
+	expected := []model.Layout{model.LayoutColumns, model.LayoutTabs, model.LayoutRows, model.LayoutAuto}
+	for n := range expected {
+		if seen[n] != expected[n] {
+			t.Errorf("expected %v, got %v", expected, seen)
+			break
+		}
+	}
+}
+
+func TestTabsText(t *testing.T) {
+	actual := tabsText([]model.Period{model.Year, model.Day})
+	expected := `["0"] Year [""]|["1"] Day [""]`
+	if actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
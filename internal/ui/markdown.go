@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeading   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownCheckbox  = regexp.MustCompile(`^(\s*)[*-]\s+\[([ xX])]\s*(.*)$`)
+	markdownListItem  = regexp.MustCompile(`^(\s*)[*-]\s+(.*)$`)
+	markdownSeparator = regexp.MustCompile(`^\s*(-{2,}|\*{3,})\s*$`)
+	markdownInline    = regexp.MustCompile(
+		"\\*\\*(.+?)\\*\\*" + // bold
+			"|__(.+?)__" + // bold
+			"|\\*([^*\\s](?:[^*]*[^*\\s])?)\\*" + // italic
+			"|\\b_([^_\\s](?:[^_]*[^_\\s])?)_\\b" + // italic
+			"|\\[([^\\]]+)]\\(([^)\\s]+)\\)" + // link
+			"|`([^`]+)`", // code
+	)
+)
+
+// renderMarkdown converts the subset of markdown used in goals to tview style tags
+func renderMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for n, line := range lines {
+		lines[n] = renderMarkdownLine(line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func renderMarkdownLine(line string) string {
+	if match := markdownHeading.FindStringSubmatch(line); match != nil {
+		if len(match[1]) == 1 {
+			return "[::bu]" + renderMarkdownInline(match[2]) + "[::-]"
+		}
+
+		return "[::b]" + renderMarkdownInline(match[2]) + "[::-]"
+	}
+
+	if match := markdownCheckbox.FindStringSubmatch(line); match != nil {
+		if match[2] == " " {
+			return match[1] + "☐ " + renderMarkdownInline(match[3])
+		}
+
+		return match[1] + "☑ [::d]" + renderMarkdownInline(match[3]) + "[::-]"
+	}
+
+	if match := markdownListItem.FindStringSubmatch(line); match != nil {
+		return match[1] + "• " + renderMarkdownInline(match[2])
+	}
+
+	if markdownSeparator.MatchString(line) {
+		return "[::d]" + strings.Repeat("─", 3) + "[::-]"
+	}
+
+	return renderMarkdownInline(line)
+}
+
+func renderMarkdownInline(text string) string {
+	var out strings.Builder
+
+	last := 0
+	for _, match := range markdownInline.FindAllStringSubmatchIndex(text, -1) {
+		out.WriteString(tview.Escape(text[last:match[0]]))
+		last = match[1]
+
+		group := func(n int) string {
+			return text[match[n*2]:match[n*2+1]]
+		}
+
+		switch {
+		case match[2] != -1:
+			out.WriteString("[::b]" + renderMarkdownInline(group(1)) + "[::B]")
+		case match[4] != -1:
+			out.WriteString("[::b]" + renderMarkdownInline(group(2)) + "[::B]")
+		case match[6] != -1:
+			out.WriteString("[::i]" + renderMarkdownInline(group(3)) + "[::I]")
+		case match[8] != -1:
+			out.WriteString("[::i]" + renderMarkdownInline(group(4)) + "[::I]")
+		case match[10] != -1:
+			url := group(6)
+			if strings.ContainsAny(url, "[]") {
+				url = "-"
+			}
+			out.WriteString("[::u:" + url + "]" + tview.Escape(group(5)) + "[::U:-]")
+		case match[14] != -1:
+			out.WriteString("[::r]" + tview.Escape(group(7)) + "[::R]")
+		}
+	}
+
+	out.WriteString(tview.Escape(text[last:]))
+
+	return out.String()
+}
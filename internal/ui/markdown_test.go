@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	type testData struct {
+		text     string
+		expected string
+	}
+
+	inputsExpecteds := []testData{
+		{"", ""},
+		{"plain text", "plain text"},
+		{"# Title", "[::bu]Title[::-]"},
+		{"## Subtitle", "[::b]Subtitle[::-]"},
+		{"* a thing to do", "• a thing to do"},
+		{"  - nested thing", "  • nested thing"},
+		{"* [ ] open task", "☐ open task"},
+		{"* [x] done task", "☑ [::d]done task[::-]"},
+		{"--", "[::d]───[::-]"},
+		{"**bold** and *italic*", "[::b]bold[::B] and [::i]italic[::I]"},
+		{"__bold__ and _italic_", "[::b]bold[::B] and [::i]italic[::I]"},
+		{"snake_case_name", "snake_case_name"},
+		{"see [docs](https://example.com)", "see [::u:https://example.com]docs[::U:-]"},
+		{"run `make test`", "run [::r]make test[::R]"},
+		{"tag-like [red] text", "tag-like [red[] text"},
+		{"* first\n* second", "• first\n• second"},
+	}
+
+	for _, inputExpected := range inputsExpecteds {
+		t.Run(fmt.Sprintf("%+v", inputExpected), func(t *testing.T) {
+			actual := renderMarkdown(inputExpected.text)
+			if actual != inputExpected.expected {
+				t.Errorf("got %q, want %q", actual, inputExpected.expected)
+			}
+		})
+	}
+}
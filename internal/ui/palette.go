@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+)
+
+type paletteItem struct {
+	title string
+	hint  string
+	// run is responsible for closing the palette, an error is shown and the palette stays open
+	run func() error
+}
+
+type PaletteDialogProps struct {
+	app         *tview.Application
+	theme       theme.Theme
+	title       string
+	placeholder string
+	items       func(query string) []paletteItem
+	onClose     func()
+}
+
+// PaletteDialog is an input with a list of items filtered by it, used for commands, search and jumps
+type PaletteDialog struct {
+	PaletteDialogProps
+
+	Primitive tview.Primitive
+
+	input *tview.InputField
+	list  *tview.List
+	items []paletteItem
+}
+
+func NewPaletteDialog(props PaletteDialogProps) *PaletteDialog {
+	d := &PaletteDialog{PaletteDialogProps: props}
+	d.initPrimitive()
+	d.render("")
+	return d
+}
+
+func (d *PaletteDialog) initPrimitive() {
+	d.input = tview.NewInputField().SetPlaceholder(d.placeholder)
+	d.input.SetFieldBackgroundColor(d.theme.Background)
+	d.input.SetPlaceholderStyle(tcell.StyleDefault.Background(d.theme.Background).Foreground(d.theme.Placeholder))
+	d.input.SetChangedFunc(d.render)
+	d.input.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			d.runSelected()
+		case tcell.KeyEscape:
+			d.onClose()
+		}
+	})
+	d.input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			d.list.SetCurrentItem(max(d.list.GetCurrentItem()-1, 0))
+			return nil
+		case tcell.KeyDown:
+			d.list.SetCurrentItem(min(d.list.GetCurrentItem()+1, d.list.GetItemCount()-1))
+			return nil
+		}
+
+		return event
+	})
+
+	d.list = tview.NewList().ShowSecondaryText(false)
+	d.list.SetSelectedBackgroundColor(d.theme.Selection)
+	d.list.SetMainTextColor(d.theme.Text)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.input, 1, 0, true).
+		AddItem(d.list, 0, 1, false)
+	layout.SetBorder(true).SetTitle(d.title).SetTitleColor(d.theme.PanelTitle).SetBorderColor(d.theme.FocusedBorder)
+
+	d.Primitive = centered(layout, 80, 20)
+}
+
+func (d *PaletteDialog) render(query string) {
+	d.items = d.PaletteDialogProps.items(query)
+
+	d.list.Clear()
+	for _, item := range d.items {
+		text := tview.Escape(item.title)
+		if item.hint != "" {
+			text = fmt.Sprintf("%s  [%s]%s[-]", text, d.theme.Placeholder.String(), tview.Escape(item.hint))
+		}
+		d.list.AddItem(text, "", 0, nil)
+	}
+}
+
+func (d *PaletteDialog) runSelected() {
+	current := d.list.GetCurrentItem()
+	if current < 0 || current >= len(d.items) {
+		return
+	}
+
+	if err := d.items[current].run(); err != nil {
+		d.input.SetLabel(fmt.Sprintf("%v: ", err))
+	}
+}
+
+func (d *PaletteDialog) Focus() {
+	d.app.SetFocus(d.input)
+}
@@ -2,7 +2,9 @@ package ui
 
 import (
 	"context"
+	"github.com/nvbn/termonizer/internal/config"
 	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
 	"github.com/rivo/tview"
 	"time"
 )
@@ -10,10 +12,19 @@ import (
 type PeriodPanelProps struct {
 	app                *tview.Application
 	timeNow            func() time.Time
+	theme              theme.Theme
+	keymap             *Keymap
+	placeholder        string
+	futurePlaceholder  string
+	autosaver          *autosaver
+	history            *history
+	editingMode        config.EditingMode
 	period             model.Period
 	goalsRepository    goalsRepository
 	settingsRepository settingsRepository
 	onFocus            func()
+	onSend             func(editor *GoalEditor, move bool)
+	isPreviewEnabled   func() bool
 }
 
 type PeriodPanel struct {
@@ -30,10 +41,19 @@ func NewPeriodPanel(ctx context.Context, props PeriodPanelProps) *PeriodPanel {
 		goalsList: NewGoalsList(ctx, GoalsListProps{
 			app:                props.app,
 			timeNow:            props.timeNow,
+			theme:              props.theme,
+			keymap:             props.keymap,
+			placeholder:        props.placeholder,
+			futurePlaceholder:  props.futurePlaceholder,
+			autosaver:          props.autosaver,
+			history:            props.history,
+			editingMode:        props.editingMode,
 			period:             props.period,
 			goalsRepository:    props.goalsRepository,
 			settingsRepository: props.settingsRepository,
 			onFocus:            props.onFocus,
+			onSend:             props.onSend,
+			isPreviewEnabled:   props.isPreviewEnabled,
 		}),
 	}
 
@@ -47,17 +67,43 @@ func (p *PeriodPanel) Focus() {
 }
 
 func (p *PeriodPanel) PrimitiveInFocus() tview.Primitive {
-	return p.goalsList.EditorInFocus().Primitive
+	return p.goalsList.EditorInFocus().PrimitiveInFocus()
+}
+
+// commands of the panel and its focused goal
+func (p *PeriodPanel) commands(ctx context.Context) []command {
+	return append(p.goalsList.commands(ctx), p.goalsList.EditorInFocus().commands(ctx)...)
+}
+
+func (p *PeriodPanel) ShowDate(ctx context.Context, dt time.Time) bool {
+	return p.goalsList.ShowDate(ctx, dt)
+}
+
+func (p *PeriodPanel) ShowGoal(ctx context.Context, id string) bool {
+	return p.goalsList.ShowGoal(ctx, id)
+}
+
+// AcceptsText is false when keys of the focused goal are commands, e.g. in the vim normal mode
+func (p *PeriodPanel) AcceptsText() bool {
+	return p.goalsList.EditorInFocus().AcceptsText()
+}
+
+func (p *PeriodPanel) ConsumesEscape() bool {
+	return p.goalsList.EditorInFocus().ConsumesEscape()
+}
+
+func (p *PeriodPanel) UpdatePreview() {
+	p.goalsList.UpdatePreview()
 }
 
 func (p *PeriodPanel) makeTopButtons(ctx context.Context) tview.Primitive {
 	topButtons := tview.NewFlex().SetDirection(tview.FlexColumn)
 
-	future := tview.NewButton(" future") // white space for centering
+	future := themedButton(p.theme, " future") // white space for centering
 	future.SetSelectedFunc(func() { p.goalsList.ScrollFuture(ctx) })
 	topButtons.AddItem(future, 0, 1, false)
 
-	now := tview.NewButton("↑ ") // white space for centering
+	now := themedButton(p.theme, "↑ ") // white space for centering
 	now.SetSelectedFunc(func() { p.goalsList.ScrollNow(ctx) })
 	topButtons.AddItem(now, 1, 0, false)
 
@@ -65,7 +111,7 @@ func (p *PeriodPanel) makeTopButtons(ctx context.Context) tview.Primitive {
 }
 
 func (p *PeriodPanel) makeBottomButton(ctx context.Context) tview.Primitive {
-	past := tview.NewButton(" past") // white space for centering
+	past := themedButton(p.theme, " past") // white space for centering
 	past.SetSelectedFunc(func() { p.goalsList.ScrollPast(ctx) })
 	return past
 }
@@ -73,7 +119,7 @@ func (p *PeriodPanel) makeBottomButton(ctx context.Context) tview.Primitive {
 func (p *PeriodPanel) initPrimitive(ctx context.Context) {
 	c := tview.NewFlex().SetDirection(tview.FlexRow)
 	c.SetFocusFunc(p.onFocus)
-	c.SetBorder(true).SetTitle(model.PeriodName(p.period))
+	c.SetBorder(true).SetTitle(model.PeriodName(p.period)).SetTitleColor(p.theme.PanelTitle)
 
 	c.AddItem(p.makeTopButtons(ctx), 1, 1, false)
 	c.AddItem(p.goalsList.Primitive, 0, 1, false)
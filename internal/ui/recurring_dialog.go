@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+	"log"
+	"strings"
+)
+
+const recurringSchedulesHelp = "daily, weekdays, monday..sunday, weekly, monthly:N, quarterly"
+
+type RecurringDialogProps struct {
+	app                 *tview.Application
+	theme               theme.Theme
+	recurringRepository recurringRepository
+	onClose             func()
+}
+
+// RecurringDialog manages items added to new goals
+type RecurringDialog struct {
+	RecurringDialogProps
+
+	Primitive tview.Primitive
+
+	list *tview.List
+	form *tview.Form
+	ids  []string
+}
+
+func NewRecurringDialog(ctx context.Context, props RecurringDialogProps) *RecurringDialog {
+	d := &RecurringDialog{RecurringDialogProps: props}
+	d.initPrimitive(ctx)
+	d.render(ctx)
+	return d
+}
+
+func (d *RecurringDialog) initPrimitive(ctx context.Context) {
+	d.list = tview.NewList()
+	d.list.SetBorder(true).SetTitle("Recurring items").SetTitleColor(d.theme.PanelTitle)
+	d.list.SetSelectedBackgroundColor(d.theme.Selection)
+	d.list.SetDoneFunc(d.onClose)
+	d.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyTab {
+			d.app.SetFocus(d.form)
+			return nil
+		}
+
+		return event
+	})
+
+	schedule := tview.NewInputField().SetLabel("Schedule").SetPlaceholder(recurringSchedulesHelp)
+	content := tview.NewInputField().SetLabel("Content").SetPlaceholder("* weekly 1:1 prep")
+
+	d.form = tview.NewForm().
+		AddFormItem(schedule).
+		AddFormItem(content).
+		AddButton("Add", func() {
+			if _, err := d.recurringRepository.Add(ctx, strings.TrimSpace(schedule.GetText()), content.GetText()); err != nil {
+				d.form.SetTitle(err.Error())
+				return
+			}
+
+			schedule.SetText("")
+			content.SetText("")
+			d.form.SetTitle("")
+			d.render(ctx)
+		}).
+		AddButton("Remove selected", func() { d.removeSelected(ctx) }).
+		AddButton("Close", d.onClose).
+		SetCancelFunc(d.onClose)
+	d.form.SetBorder(true).SetTitleColor(d.theme.PastTitle)
+	d.form.SetButtonStyle(tcell.StyleDefault.Background(d.theme.Button).Foreground(d.theme.ButtonText))
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.list, 0, 1, true).
+		AddItem(d.form, 9, 0, false)
+
+	d.Primitive = centered(layout, 80, 24)
+}
+
+func (d *RecurringDialog) removeSelected(ctx context.Context) {
+	current := d.list.GetCurrentItem()
+	if current < 0 || current >= len(d.ids) {
+		return
+	}
+
+	if err := d.recurringRepository.Remove(ctx, d.ids[current]); err != nil {
+		log.Fatalf("failed to remove recurring: %v", err)
+	}
+
+	d.render(ctx)
+}
+
+func (d *RecurringDialog) render(ctx context.Context) {
+	items, err := d.recurringRepository.List(ctx)
+	if err != nil {
+		log.Fatalf("failed to list recurring: %v", err)
+	}
+
+	d.list.Clear()
+	d.ids = make([]string, 0, len(items))
+	for _, item := range items {
+		d.list.AddItem(fmt.Sprintf("%s: %s", item.Schedule, strings.ReplaceAll(item.Content, "\n", " ")), "", 0, nil)
+		d.ids = append(d.ids, item.ID)
+	}
+}
+
+func (d *RecurringDialog) Focus() {
+	d.app.SetFocus(d.list)
+}
+
+// centered puts the primitive in the middle of the screen, used for dialogs
+func centered(p tview.Primitive, width int, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/utils"
+	"strings"
+	"time"
+)
+
+// lineRange finds the lines touched by the selection, the range doesn't include the last newline
+func lineRange(text string, start int, end int) (int, int) {
+	start, end = min(start, len(text)), min(end, len(text))
+
+	// a selection of whole lines ends after the newline, the next line isn't selected
+	if end > start && text[end-1] == '\n' {
+		end--
+	}
+
+	from := strings.LastIndexByte(text[:start], '\n') + 1
+	to := strings.IndexByte(text[end:], '\n')
+	if to == -1 {
+		return from, len(text)
+	}
+
+	return from, end + to
+}
+
+// removeLines removes lines found by lineRange together with their newline
+func removeLines(text string, from int, to int) string {
+	if to < len(text) {
+		return text[:from] + text[to+1:]
+	} else if from > 0 {
+		return text[:from-1]
+	}
+
+	return ""
+}
+
+func appendLines(text string, lines string) string {
+	text = strings.TrimRight(text, "\n")
+	if text == "" {
+		return lines
+	}
+
+	return text + "\n" + lines
+}
+
+// sendTarget is a goal lines could be moved or copied to, the goal is found by the period and the date
+type sendTarget struct {
+	title  string
+	period model.Period
+	start  time.Time
+}
+
+// sendTargets lists the next and the previous goals of the period, the parent goal and days of the week, the next
+// goal goes first as postponing is the most common
+func sendTargets(goal model.Goal) []sendTarget {
+	targets := make([]sendTarget, 0)
+	add := func(title string, period model.Period, dt time.Time) {
+		target := model.NewGoal(period, dt)
+		if target.Period == goal.Period && target.CompareStart(goal.Start) == 0 {
+			return
+		}
+
+		for _, existing := range targets {
+			if existing.period == period && target.CompareStart(existing.start) == 0 {
+				return
+			}
+		}
+
+		targets = append(targets, sendTarget{
+			title:  fmt.Sprintf("%s %s", title, target.FormatStart()),
+			period: period,
+			start:  target.Start,
+		})
+	}
+
+	name := strings.ToLower(model.PeriodName(goal.Period))
+	add("Next "+name, goal.Period, goal.End())
+	add("Previous "+name, goal.Period, goal.Start.AddDate(0, 0, -1))
+
+	if goal.Period != model.Year {
+		add(model.PeriodName(goal.Period-1), goal.Period-1, goal.Start)
+	}
+
+	if goal.Period == model.Week || goal.Period == model.Day {
+		weekStart := utils.WeekStart(goal.Start)
+		for n := range 7 {
+			add(model.PeriodName(model.Day), model.Day, weekStart.AddDate(0, 0, n))
+		}
+	}
+
+	return targets
+}
@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/utils"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLineRange(t *testing.T) {
+	text := "first\nsecond\nthird"
+
+	inputsExpecteds := []struct {
+		start    int
+		end      int
+		expected string
+		removed  string
+	}{
+		{0, 0, "first", "second\nthird"},
+		{8, 8, "second", "first\nthird"},
+		{6, 6, "second", "first\nthird"},
+		{len(text), len(text), "third", "first\nsecond"},
+		// selections touching several lines
+		{3, 9, "first\nsecond", "third"},
+		{6, 13, "second", "first\nthird"},
+		{2, len(text), text, ""},
+	}
+
+	for _, tt := range inputsExpecteds {
+		from, to := lineRange(text, tt.start, tt.end)
+		if text[from:to] != tt.expected {
+			t.Errorf("%d-%d: expected %q, got %q", tt.start, tt.end, tt.expected, text[from:to])
+		}
+
+		if removed := removeLines(text, from, to); removed != tt.removed {
+			t.Errorf("%d-%d: expected %q, got %q", tt.start, tt.end, tt.removed, removed)
+		}
+	}
+}
+
+func TestAppendLines(t *testing.T) {
+	inputsExpecteds := []struct {
+		text     string
+		expected string
+	}{
+		{"", "* [ ] item"},
+		{"* plan", "* plan\n* [ ] item"},
+		{"* plan\n\n", "* plan\n* [ ] item"},
+	}
+
+	for _, tt := range inputsExpecteds {
+		if actual := appendLines(tt.text, "* [ ] item"); actual != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.text, tt.expected, actual)
+		}
+	}
+}
+
+func TestSendTargets(t *testing.T) {
+	utils.SetWeekStart(time.Monday)
+
+	titles := func(goal model.Goal) []string {
+		targets := sendTargets(goal)
+		titles := make([]string, 0, len(targets))
+		for _, target := range targets {
+			titles = append(titles, target.title)
+		}
+		return titles
+	}
+
+	day := model.NewGoalForDay(time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local))
+	expected := []string{
+		"Next day 2024-12-11 Wednesday",
+		"Previous day 2024-12-09 Monday",
+		"Week 2024-12-09 W50",
+		"Day 2024-12-12 Thursday",
+		"Day 2024-12-13 Friday",
+		"Day 2024-12-14 Saturday",
+		"Day 2024-12-15 Sunday",
+	}
+	if actual := titles(day); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	week := model.NewGoalForWeek(time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local))
+	expected = []string{
+		"Next week 2024-12-16 W51",
+		"Previous week 2024-12-02 W49",
+		"Quarter 2024 Q4",
+		"Day 2024-12-09 Monday",
+		"Day 2024-12-10 Tuesday",
+		"Day 2024-12-11 Wednesday",
+		"Day 2024-12-12 Thursday",
+		"Day 2024-12-13 Friday",
+		"Day 2024-12-14 Saturday",
+		"Day 2024-12-15 Sunday",
+	}
+	if actual := titles(week); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	year := model.NewGoalForYear(time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local))
+	expected = []string{"Next year 2025", "Previous year 2023"}
+	if actual := titles(year); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
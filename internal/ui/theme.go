@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+)
+
+// applyTheme sets defaults for all primitives, should be called before creating them
+func applyTheme(th theme.Theme) {
+	tview.Styles.PrimitiveBackgroundColor = th.Background
+	tview.Styles.ContrastBackgroundColor = th.Button
+	tview.Styles.MoreContrastBackgroundColor = th.Selection
+	tview.Styles.BorderColor = th.Border
+	tview.Styles.TitleColor = th.PanelTitle
+	tview.Styles.GraphicsColor = th.Border
+	tview.Styles.PrimaryTextColor = th.Text
+	tview.Styles.SecondaryTextColor = th.NowTitle
+	tview.Styles.TertiaryTextColor = th.FutureTitle
+	tview.Styles.InverseTextColor = th.Button
+	tview.Styles.ContrastSecondaryTextColor = th.ButtonText
+}
+
+func themedButton(th theme.Theme, label string) *tview.Button {
+	button := tview.NewButton(label)
+	button.SetStyle(tcell.StyleDefault.Background(th.Button).Foreground(th.ButtonText))
+	button.SetActivatedStyle(tcell.StyleDefault.Background(th.FocusedBorder).Foreground(th.Background))
+	return button
+}
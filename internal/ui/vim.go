@@ -0,0 +1,678 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type vimMode int
+
+const (
+	vimNormal vimMode = iota
+	vimInsert
+	vimVisual
+)
+
+// keys that aren't runes, text is never fed with them
+const (
+	vimKeyEsc       rune = 0x1b
+	vimKeyEnter     rune = '\n'
+	vimKeyBackspace rune = '\b'
+	vimKeyRedo      rune = 0x12 // ctrl+r
+)
+
+// vimHelp describes keys of the normal mode for the help, keys are separated by spaces,
+// TestVimHelp_AllKeys checks that every handled key is described
+var vimHelp = [][2]string{
+	{"Esc", "Leave the insert or visual mode"},
+	{"h j k l w b e 0 $ gg G", "Motions, with counts like 3w"},
+	{"d c y", "Operators with motions or doubled for lines, e.g. d2w or 3dd"},
+	{"x p P i a I A o O v", "Delete, put, insert, open a line and the visual mode"},
+	{"u ⌃R .", "Undo, redo and repeat the last change"},
+}
+
+type vimResult int
+
+const (
+	// vimPass lets the text area handle the key, e.g. typing in the insert mode
+	vimPass vimResult = iota
+	vimHandled
+	vimUndo
+	vimRedo
+)
+
+// vimBuffer is the text with the cursor as a byte offset, in the visual mode the selection is from visualStart
+// to the cursor including both
+type vimBuffer struct {
+	text   string
+	cursor int
+}
+
+type vimCommand struct {
+	count       int
+	operator    rune
+	motionCount int
+	key         rune
+}
+
+func (c vimCommand) total() int {
+	return max(c.count, 1) * max(c.motionCount, 1)
+}
+
+// vim is a modal editing layer, it's independent of tview, so it's tested on plain strings
+type vim struct {
+	copy  func(text string)
+	paste func() string
+
+	mode        vimMode
+	pending     []rune
+	visualStart int
+
+	// keys of the last change and the change being typed for dot-repeat, insert mode text is recorded too
+	lastChange []rune
+	recording  []rune
+	replaying  bool
+}
+
+func newVim(copy func(text string), paste func() string) *vim {
+	return &vim{copy: copy, paste: paste}
+}
+
+func (v *vim) modeName() string {
+	switch v.mode {
+	case vimInsert:
+		return "insert"
+	case vimVisual:
+		return "visual"
+	default:
+		return "normal"
+	}
+}
+
+// consumesEscape is true when Esc leaves a mode or cancels a command instead of counting towards the exit
+func (v *vim) consumesEscape() bool {
+	return v.mode != vimNormal || len(v.pending) > 0
+}
+
+// selection returns the visual mode selection as a half-open interval
+func (v *vim) selection(b *vimBuffer) (int, int) {
+	start, end := min(v.visualStart, b.cursor), max(v.visualStart, b.cursor)
+	return start, nextRune(b.text, end)
+}
+
+// buffer restores the cursor from the text area selection, which is always ordered
+func (v *vim) buffer(text string, start int, end int) *vimBuffer {
+	if v.mode == vimVisual && start != end {
+		if start == v.visualStart {
+			return &vimBuffer{text: text, cursor: prevRune(text, end)}
+		}
+		return &vimBuffer{text: text, cursor: start}
+	}
+
+	// e.g. a mouse selection
+	if v.mode == vimVisual {
+		v.mode = vimNormal
+	}
+
+	return &vimBuffer{text: text, cursor: start}
+}
+
+// shownSelection is what the text area should select, only the visual mode has a selection
+func (v *vim) shownSelection(b *vimBuffer) (int, int) {
+	if v.mode == vimVisual {
+		return v.selection(b)
+	}
+
+	return b.cursor, b.cursor
+}
+
+func nextRune(text string, pos int) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+
+	_, size := utf8.DecodeRuneInString(text[pos:])
+	return pos + size
+}
+
+func prevRune(text string, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+
+	_, size := utf8.DecodeLastRuneInString(text[:pos])
+	return pos - size
+}
+
+func lineStart(text string, pos int) int {
+	return strings.LastIndexByte(text[:pos], '\n') + 1
+}
+
+func lineEnd(text string, pos int) int {
+	if idx := strings.IndexByte(text[pos:], '\n'); idx != -1 {
+		return pos + idx
+	}
+
+	return len(text)
+}
+
+// runeClass splits text into words like vim: blanks, letters with digits and underscores, and other symbols
+func runeClass(text string, pos int) int {
+	r, _ := utf8.DecodeRuneInString(text[pos:])
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return 1
+	default:
+		return 2
+	}
+}
+
+// clamp keeps the cursor on a character, only an empty line has the cursor at its end
+func clamp(b *vimBuffer) {
+	b.cursor = min(max(b.cursor, 0), len(b.text))
+	if b.cursor == lineEnd(b.text, b.cursor) && b.cursor > lineStart(b.text, b.cursor) {
+		b.cursor = prevRune(b.text, b.cursor)
+	}
+}
+
+func wordForward(text string, pos int) int {
+	if pos >= len(text) {
+		return pos
+	}
+
+	if class := runeClass(text, pos); class != 0 {
+		for pos < len(text) && runeClass(text, pos) == class {
+			pos = nextRune(text, pos)
+		}
+	}
+
+	for pos < len(text) && runeClass(text, pos) == 0 {
+		pos = nextRune(text, pos)
+	}
+
+	return pos
+}
+
+func wordBackward(text string, pos int) int {
+	pos = prevRune(text, pos)
+	for pos > 0 && runeClass(text, pos) == 0 {
+		pos = prevRune(text, pos)
+	}
+
+	class := runeClass(text, pos)
+	for pos > 0 && runeClass(text, prevRune(text, pos)) == class {
+		pos = prevRune(text, pos)
+	}
+
+	return pos
+}
+
+func wordEnd(text string, pos int) int {
+	pos = nextRune(text, pos)
+	for pos < len(text) && runeClass(text, pos) == 0 {
+		pos = nextRune(text, pos)
+	}
+
+	if pos >= len(text) {
+		return prevRune(text, len(text))
+	}
+
+	class := runeClass(text, pos)
+	for next := nextRune(text, pos); next < len(text) && runeClass(text, next) == class; next = nextRune(text, next) {
+		pos = next
+	}
+
+	return pos
+}
+
+// lineAt returns the start of the line with the number from 1, the last line when there're fewer lines
+func lineAt(text string, number int) int {
+	pos := 0
+	for n := 1; n < number; n++ {
+		end := lineEnd(text, pos)
+		if end == len(text) {
+			break
+		}
+		pos = end + 1
+	}
+
+	return pos
+}
+
+// verticalMove moves the cursor by lines keeping the column
+func verticalMove(text string, pos int, lines int) int {
+	column := utf8.RuneCountInString(text[lineStart(text, pos):pos])
+
+	start := lineStart(text, pos)
+	for ; lines > 0; lines-- {
+		end := lineEnd(text, start)
+		if end == len(text) {
+			break
+		}
+		start = end + 1
+	}
+	for ; lines < 0 && start > 0; lines++ {
+		start = lineStart(text, start-1)
+	}
+
+	pos = start
+	end := lineEnd(text, start)
+	for ; column > 0 && pos < end; column-- {
+		pos = nextRune(text, pos)
+	}
+
+	return pos
+}
+
+// motion returns the target of the motion, inclusive motions cover the character under the target,
+// linewise motions cover whole lines when used with operators
+func motion(text string, pos int, key rune, count int, hasCount bool) (target int, inclusive bool, linewise bool, ok bool) {
+	switch key {
+	case 'h', vimKeyBackspace:
+		for ; count > 0 && pos > lineStart(text, pos); count-- {
+			pos = prevRune(text, pos)
+		}
+		return pos, false, false, true
+	case 'l', ' ':
+		for ; count > 0 && pos < lineEnd(text, pos); count-- {
+			pos = nextRune(text, pos)
+		}
+		return pos, false, false, true
+	case 'j', vimKeyEnter:
+		return verticalMove(text, pos, count), false, true, true
+	case 'k':
+		return verticalMove(text, pos, -count), false, true, true
+	case 'w':
+		for ; count > 0; count-- {
+			pos = wordForward(text, pos)
+		}
+		return pos, false, false, true
+	case 'b':
+		for ; count > 0; count-- {
+			pos = wordBackward(text, pos)
+		}
+		return pos, false, false, true
+	case 'e':
+		for ; count > 0; count-- {
+			pos = wordEnd(text, pos)
+		}
+		return pos, true, false, true
+	case '0':
+		return lineStart(text, pos), false, false, true
+	case '$':
+		return lineEnd(text, pos), false, false, true
+	case 'g':
+		if !hasCount {
+			count = 1
+		}
+		return lineAt(text, count), false, true, true
+	case 'G':
+		if !hasCount {
+			return lineStart(text, len(text)), false, true, true
+		}
+		return lineAt(text, count), false, true, true
+	default:
+		return pos, false, false, false
+	}
+}
+
+func isMotion(key rune) bool {
+	return strings.ContainsRune("hjklwbe0$gG \n\b", key)
+}
+
+func readCount(keys []rune, i *int) int {
+	count := 0
+	for *i < len(keys) && keys[*i] >= '0' && keys[*i] <= '9' && (count > 0 || keys[*i] != '0') {
+		count = count*10 + int(keys[*i]-'0')
+		*i++
+	}
+
+	return count
+}
+
+// parse reads "[count][operator[count]]key", "gg" is parsed as 'g'
+func (v *vim) parse(keys []rune) (cmd vimCommand, complete bool, valid bool) {
+	i := 0
+	cmd.count = readCount(keys, &i)
+	if i == len(keys) {
+		return cmd, false, true
+	}
+
+	if strings.ContainsRune("dcy", keys[i]) && v.mode == vimNormal {
+		cmd.operator = keys[i]
+		i++
+		cmd.motionCount = readCount(keys, &i)
+		if i == len(keys) {
+			return cmd, false, true
+		}
+	}
+
+	cmd.key = keys[i]
+	if cmd.key == 'g' {
+		if i+1 == len(keys) {
+			return cmd, false, true
+		} else if keys[i+1] != 'g' {
+			return cmd, false, false
+		}
+		i++
+	}
+
+	if i != len(keys)-1 {
+		return cmd, false, false
+	}
+
+	if cmd.operator != 0 && cmd.key != cmd.operator && !isMotion(cmd.key) {
+		return cmd, false, false
+	}
+
+	return cmd, true, true
+}
+
+// Key handles a key, in the insert mode only Esc is handled and the rest is recorded for dot-repeat
+func (v *vim) Key(b *vimBuffer, key rune) vimResult {
+	if v.mode == vimInsert {
+		return v.insertKey(b, key)
+	}
+
+	if key == vimKeyEsc {
+		v.pending = nil
+		v.recording = nil
+		v.mode = vimNormal
+		clamp(b)
+		return vimHandled
+	}
+
+	v.pending = append(v.pending, key)
+	cmd, complete, valid := v.parse(v.pending)
+	if !valid {
+		v.pending = nil
+		return vimHandled
+	} else if !complete {
+		return vimHandled
+	}
+
+	keys := v.pending
+	v.pending = nil
+
+	if v.mode == vimVisual {
+		return v.visual(b, cmd)
+	}
+
+	return v.normal(b, cmd, keys)
+}
+
+func (v *vim) insertKey(b *vimBuffer, key rune) vimResult {
+	if key == vimKeyEsc {
+		v.mode = vimNormal
+		if !v.replaying && v.recording != nil {
+			v.lastChange = append(v.recording, vimKeyEsc)
+			v.recording = nil
+		}
+
+		// like vim, the cursor is moved back on the last inserted character
+		if b.cursor > lineStart(b.text, b.cursor) {
+			b.cursor = prevRune(b.text, b.cursor)
+		}
+		clamp(b)
+		return vimHandled
+	}
+
+	if !v.replaying {
+		if v.recording != nil {
+			v.recording = append(v.recording, key)
+		}
+		return vimPass
+	}
+
+	switch key {
+	case vimKeyBackspace:
+		if b.cursor > 0 {
+			start := prevRune(b.text, b.cursor)
+			b.text = b.text[:start] + b.text[b.cursor:]
+			b.cursor = start
+		}
+	default:
+		b.text = b.text[:b.cursor] + string(key) + b.text[b.cursor:]
+		b.cursor += utf8.RuneLen(key)
+	}
+
+	return vimHandled
+}
+
+// enterInsert switches to the insert mode, the change is recorded until Esc
+func (v *vim) enterInsert(keys []rune) {
+	v.mode = vimInsert
+	if !v.replaying {
+		v.recording = append([]rune{}, keys...)
+	}
+}
+
+// changed remembers a change that doesn't enter the insert mode for dot-repeat
+func (v *vim) changed(keys []rune) {
+	if !v.replaying {
+		v.lastChange = append([]rune{}, keys...)
+	}
+}
+
+func (v *vim) deleteRange(b *vimBuffer, start int, end int, yank string) {
+	v.copy(yank)
+	b.text = b.text[:start] + b.text[end:]
+	b.cursor = start
+}
+
+// operatorRange returns the range affected by the operator with the motion, the yanked text of linewise ranges
+// always ends with a new line, so it's pasted as lines
+func (v *vim) operatorRange(b *vimBuffer, cmd vimCommand) (start int, end int, linewise bool, ok bool) {
+	if cmd.key == cmd.operator {
+		from := lineStart(b.text, b.cursor)
+		to := lineEnd(b.text, verticalMove(b.text, b.cursor, cmd.total()-1))
+		return from, to, true, true
+	}
+
+	key := cmd.key
+	// like vim, cw changes to the end of the word
+	if cmd.operator == 'c' && key == 'w' && b.cursor < len(b.text) && runeClass(b.text, b.cursor) != 0 {
+		key = 'e'
+	}
+
+	target, inclusive, linewise, ok := motion(b.text, b.cursor, key, cmd.total(), cmd.count > 0 || cmd.motionCount > 0)
+	if !ok {
+		return 0, 0, false, false
+	}
+
+	start, end = min(b.cursor, target), max(b.cursor, target)
+	if linewise {
+		return lineStart(b.text, start), lineEnd(b.text, end), true, true
+	}
+
+	if inclusive {
+		end = nextRune(b.text, end)
+	}
+
+	// dw on the last word of the line doesn't join the next line
+	if key == 'w' && end > lineEnd(b.text, start) {
+		end = lineEnd(b.text, start)
+	}
+
+	return start, end, false, true
+}
+
+func (v *vim) operator(b *vimBuffer, cmd vimCommand, keys []rune) vimResult {
+	start, end, linewise, ok := v.operatorRange(b, cmd)
+	if !ok {
+		return vimHandled
+	}
+
+	yank := b.text[start:end]
+	if linewise {
+		yank += "\n"
+	}
+
+	switch cmd.operator {
+	case 'y':
+		v.copy(yank)
+		b.cursor = start
+	case 'd':
+		if linewise {
+			// the new line before or after the lines goes with them
+			if end < len(b.text) {
+				end++
+			} else if start > 0 {
+				start--
+			}
+		}
+
+		v.deleteRange(b, start, end, yank)
+		if linewise {
+			b.cursor = lineStart(b.text, b.cursor)
+		}
+		v.changed(keys)
+	case 'c':
+		// the line itself is kept empty
+		v.deleteRange(b, start, end, yank)
+		v.enterInsert(keys)
+		return vimHandled
+	}
+
+	clamp(b)
+	return vimHandled
+}
+
+func (v *vim) put(b *vimBuffer, after bool, count int, keys []rune) {
+	text := v.paste()
+	if text == "" {
+		return
+	}
+
+	if strings.HasSuffix(text, "\n") {
+		lines := strings.Repeat(text, count)
+		if !after {
+			b.cursor = lineStart(b.text, b.cursor)
+			b.text = b.text[:b.cursor] + lines + b.text[b.cursor:]
+		} else if end := lineEnd(b.text, b.cursor); end == len(b.text) {
+			b.text = b.text + "\n" + strings.TrimSuffix(lines, "\n")
+			b.cursor = end + 1
+		} else {
+			b.text = b.text[:end+1] + lines + b.text[end+1:]
+			b.cursor = end + 1
+		}
+	} else {
+		pos := b.cursor
+		if after && pos < lineEnd(b.text, pos) {
+			pos = nextRune(b.text, pos)
+		}
+
+		inserted := strings.Repeat(text, count)
+		b.text = b.text[:pos] + inserted + b.text[pos:]
+		b.cursor = prevRune(b.text, pos+len(inserted))
+	}
+
+	v.changed(keys)
+	clamp(b)
+}
+
+func (v *vim) normal(b *vimBuffer, cmd vimCommand, keys []rune) vimResult {
+	if cmd.operator != 0 {
+		return v.operator(b, cmd, keys)
+	}
+
+	if target, _, _, ok := motion(b.text, b.cursor, cmd.key, cmd.total(), cmd.count > 0); ok {
+		b.cursor = target
+		clamp(b)
+		return vimHandled
+	}
+
+	switch cmd.key {
+	case 'i':
+		v.enterInsert(keys)
+	case 'a':
+		if b.cursor < lineEnd(b.text, b.cursor) {
+			b.cursor = nextRune(b.text, b.cursor)
+		}
+		v.enterInsert(keys)
+	case 'I':
+		b.cursor = lineStart(b.text, b.cursor)
+		v.enterInsert(keys)
+	case 'A':
+		b.cursor = lineEnd(b.text, b.cursor)
+		v.enterInsert(keys)
+	case 'o':
+		b.cursor = lineEnd(b.text, b.cursor)
+		b.text = b.text[:b.cursor] + "\n" + b.text[b.cursor:]
+		b.cursor++
+		v.enterInsert(keys)
+	case 'O':
+		b.cursor = lineStart(b.text, b.cursor)
+		b.text = b.text[:b.cursor] + "\n" + b.text[b.cursor:]
+		v.enterInsert(keys)
+	case 'x':
+		end := b.cursor
+		for n := 0; n < cmd.total() && end < lineEnd(b.text, b.cursor); n++ {
+			end = nextRune(b.text, end)
+		}
+		if end > b.cursor {
+			v.deleteRange(b, b.cursor, end, b.text[b.cursor:end])
+			v.changed(keys)
+		}
+		clamp(b)
+	case 'p', 'P':
+		v.put(b, cmd.key == 'p', cmd.total(), keys)
+	case 'v':
+		v.mode = vimVisual
+		v.visualStart = b.cursor
+	case 'u':
+		return vimUndo
+	case vimKeyRedo:
+		return vimRedo
+	case '.':
+		v.repeat(b, cmd.total())
+	}
+
+	return vimHandled
+}
+
+func (v *vim) repeat(b *vimBuffer, count int) {
+	if len(v.lastChange) == 0 {
+		return
+	}
+
+	v.replaying = true
+	defer func() { v.replaying = false }()
+
+	for ; count > 0; count-- {
+		for _, key := range v.lastChange {
+			v.Key(b, key)
+		}
+	}
+}
+
+func (v *vim) visual(b *vimBuffer, cmd vimCommand) vimResult {
+	if target, _, _, ok := motion(b.text, b.cursor, cmd.key, cmd.total(), cmd.count > 0); ok {
+		b.cursor = target
+		clamp(b)
+		return vimHandled
+	}
+
+	start, end := v.selection(b)
+	switch cmd.key {
+	case 'y':
+		v.copy(b.text[start:end])
+		b.cursor = start
+	case 'd', 'x':
+		v.deleteRange(b, start, end, b.text[start:end])
+	case 'c':
+		v.deleteRange(b, start, end, b.text[start:end])
+		v.mode = vimInsert
+		return vimHandled
+	case 'v':
+	default:
+		return vimHandled
+	}
+
+	v.mode = vimNormal
+	clamp(b)
+	return vimHandled
+}
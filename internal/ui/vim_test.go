@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// feed sends keys to vim, passed keys are typed like the text area does in the insert mode
+func feed(v *vim, b *vimBuffer, keys string) []vimResult {
+	results := make([]vimResult, 0)
+	for _, key := range keys {
+		result := v.Key(b, key)
+		if result == vimPass {
+			b.text = b.text[:b.cursor] + string(key) + b.text[b.cursor:]
+			b.cursor += len(string(key))
+		} else if result != vimHandled {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+func newTestVim() (*vim, *string) {
+	register := ""
+	return newVim(func(text string) { register = text }, func() string { return register }), &register
+}
+
+func TestVim_Key(t *testing.T) {
+	inputsExpecteds := []struct {
+		name     string
+		text     string
+		cursor   int
+		keys     string
+		expected string
+		position int
+		mode     vimMode
+	}{
+		{"motions", "one two three", 0, "w", "one two three", 4, vimNormal},
+		{"counted motion", "one two three", 0, "2w", "one two three", 8, vimNormal},
+		{"word end", "one two three", 0, "ee", "one two three", 6, vimNormal},
+		{"word back", "one two three", 8, "b", "one two three", 4, vimNormal},
+		{"line end", "one\ntwo", 0, "$", "one\ntwo", 2, vimNormal},
+		{"line start", "one\ntwo", 6, "0", "one\ntwo", 4, vimNormal},
+		{"last line", "one\ntwo\nthree", 1, "G", "one\ntwo\nthree", 8, vimNormal},
+		{"first line", "one\ntwo\nthree", 9, "gg", "one\ntwo\nthree", 0, vimNormal},
+		{"line number", "one\ntwo\nthree", 0, "2G", "one\ntwo\nthree", 4, vimNormal},
+		{"down keeps column", "one\ntwo", 1, "j", "one\ntwo", 5, vimNormal},
+		{"delete word", "one two three", 0, "dw", "two three", 0, vimNormal},
+		{"delete words", "one two three", 0, "d2w", "three", 0, vimNormal},
+		{"counted delete", "one two three", 0, "2dw", "three", 0, vimNormal},
+		{"delete last word", "one two\nthree", 4, "dw", "one \nthree", 3, vimNormal},
+		{"delete to end", "one two", 4, "d$", "one ", 3, vimNormal},
+		{"delete line", "one\ntwo\nthree", 4, "dd", "one\nthree", 4, vimNormal},
+		{"delete last line", "one\ntwo", 5, "dd", "one", 0, vimNormal},
+		{"delete lines", "one\ntwo\nthree", 0, "2dd", "three", 0, vimNormal},
+		{"delete to last line", "one\ntwo\nthree", 4, "dG", "one", 0, vimNormal},
+		{"change word", "one two", 0, "cwsix\x1b", "six two", 2, vimNormal},
+		{"change line", "one\ntwo", 4, "ccsix\x1b", "one\nsix", 6, vimNormal},
+		{"delete char", "one", 0, "2x", "e", 0, vimNormal},
+		{"insert", "one", 1, "ix\x1b", "oxne", 1, vimNormal},
+		{"append", "one", 0, "ax\x1b", "oxne", 1, vimNormal},
+		{"append line", "one\ntwo", 0, "Ax\x1b", "onex\ntwo", 3, vimNormal},
+		{"insert line start", "one", 2, "Ix\x1b", "xone", 0, vimNormal},
+		{"open below", "one\ntwo", 0, "ox\x1b", "one\nx\ntwo", 4, vimNormal},
+		{"open above", "one\ntwo", 5, "Ox\x1b", "one\nx\ntwo", 4, vimNormal},
+		{"still inserting", "one", 0, "ix", "xone", 1, vimInsert},
+		{"yank and put", "one two", 0, "ywP", "one one two", 3, vimNormal},
+		{"yank line and put", "one\ntwo", 0, "yyjp", "one\ntwo\none", 8, vimNormal},
+		{"put line above", "one\ntwo", 4, "yykP", "two\none\ntwo", 0, vimNormal},
+		{"delete and put", "one two", 0, "dwp", "tone wo", 4, vimNormal},
+		{"visual delete", "one two", 1, "vld", "o two", 1, vimNormal},
+		{"visual backwards", "one two", 5, "vhhd", "oneo", 3, vimNormal},
+		{"visual yank", "one two", 4, "vey0P", "twoone two", 2, vimNormal},
+		{"visual change", "one two", 0, "vecsix\x1b", "six two", 2, vimNormal},
+		{"visual", "one two", 0, "vw", "one two", 4, vimVisual},
+		{"dot repeat delete", "one two three four", 0, "dw..", "four", 0, vimNormal},
+		{"dot repeat change", "one two", 0, "cwsix\x1bw.", "six six", 6, vimNormal},
+		{"dot repeat insert", "ab", 0, "ix\x1bl.", "xxab", 1, vimNormal},
+		{"counted dot repeat", "a b c d", 0, "x2.", " c d", 0, vimNormal},
+		{"cancelled command", "one two", 0, "d\x1bw", "one two", 4, vimNormal},
+		{"unknown command", "one two", 0, "dqw", "one two", 4, vimNormal},
+		{"unicode", "привет мир", 0, "wx", "привет ир", 13, vimNormal},
+		{"empty", "", 0, "ddxp$", "\n", 1, vimNormal},
+	}
+
+	for _, tt := range inputsExpecteds {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _ := newTestVim()
+			b := &vimBuffer{text: tt.text, cursor: tt.cursor}
+			feed(v, b, tt.keys)
+
+			if b.text != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, b.text)
+			}
+
+			if b.cursor != tt.position {
+				t.Errorf("expected cursor at %d, got %d", tt.position, b.cursor)
+			}
+
+			if v.mode != tt.mode {
+				t.Errorf("expected mode %d, got %s", tt.mode, v.modeName())
+			}
+		})
+	}
+}
+
+func TestVim_Undo(t *testing.T) {
+	v, _ := newTestVim()
+	b := &vimBuffer{text: "one"}
+
+	results := feed(v, b, "xu\x12")
+	if len(results) != 2 || results[0] != vimUndo || results[1] != vimRedo {
+		t.Errorf("expected undo and redo, got %v", results)
+	}
+}
+
+func TestVim_ConsumesEscape(t *testing.T) {
+	v, _ := newTestVim()
+	b := &vimBuffer{text: "one"}
+
+	inputsExpecteds := []struct {
+		keys     string
+		expected bool
+	}{
+		{"", false},
+		{"i", true},
+		{"\x1b", false},
+		{"d", true},
+		{"\x1bv", true},
+		{"\x1b", false},
+	}
+
+	for _, tt := range inputsExpecteds {
+		feed(v, b, tt.keys)
+		if v.consumesEscape() != tt.expected {
+			t.Errorf("after %q expected %v, got %v", tt.keys, tt.expected, v.consumesEscape())
+		}
+	}
+}
+
+func TestVim_Buffer(t *testing.T) {
+	v, _ := newTestVim()
+	b := &vimBuffer{text: "one two", cursor: 4}
+	feed(v, b, "vb")
+
+	start, end := v.shownSelection(b)
+	if start != 0 || end != 5 {
+		t.Errorf("expected selection 0-5, got %d-%d", start, end)
+	}
+
+	restored := v.buffer(b.text, start, end)
+	if restored.cursor != 0 {
+		t.Errorf("expected cursor at 0, got %d", restored.cursor)
+	}
+
+	// e.g. clicked with the mouse
+	v.buffer(b.text, 2, 2)
+	if v.mode != vimNormal {
+		t.Errorf("expected normal mode, got %s", v.modeName())
+	}
+}
+
+func TestVimHelp_AllKeys(t *testing.T) {
+	listed := make(map[rune]bool)
+	for _, line := range vimHelp {
+		for _, key := range strings.Fields(line[0]) {
+			r, _ := utf8.DecodeRuneInString(key)
+			switch key {
+			case "Esc":
+				r = vimKeyEsc
+			case "⌃R":
+				r = vimKeyRedo
+			}
+			listed[r] = true
+		}
+	}
+
+	// counts are described with motions
+	for r := rune('!'); r <= '~'; r++ {
+		if r >= '1' && r <= '9' {
+			continue
+		}
+
+		v := newVim(func(string) {}, func() string { return "pasted" })
+		v.lastChange = []rune("x")
+		b := &vimBuffer{text: "one two\nthree four\nfive", cursor: 10}
+		result := v.Key(b, r)
+
+		acted := result != vimHandled || b.text != "one two\nthree four\nfive" || b.cursor != 10 ||
+			v.mode != vimNormal || len(v.pending) > 0
+		if acted && !listed[r] {
+			t.Errorf("%q is handled, but it isn't in the help", r)
+		} else if !acted && listed[r] {
+			t.Errorf("%q is in the help, but it isn't handled", r)
+		}
+	}
+}
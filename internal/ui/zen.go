@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/nvbn/termonizer/internal/theme"
+	"github.com/rivo/tview"
+	"log"
+)
+
+const zenSidebarWidth = 40
+
+// parentGoal finds the goal of the longer period containing the start of the goal, years don't have parents
+func parentGoal(ctx context.Context, goalsRepository goalsRepository, goal model.Goal) (model.Goal, bool) {
+	if goal.Period == model.Year {
+		return model.Goal{}, false
+	}
+
+	goals, err := goalsRepository.FindForPeriod(ctx, goal.Period-1)
+	if err != nil {
+		log.Fatalf("failed to find goals: %v", err)
+	}
+
+	for _, parent := range goals {
+		if parent.CompareStart(goal.Start) == 0 {
+			return parent, true
+		}
+	}
+
+	return model.Goal{}, false
+}
+
+type ZenViewProps struct {
+	theme  theme.Theme
+	editor *GoalEditor
+	parent *model.Goal // nil without the sidebar
+	width  int
+}
+
+// ZenView shows a single goal with a readable width in the middle of the screen
+type ZenView struct {
+	ZenViewProps
+
+	Primitive *tview.Flex
+}
+
+func NewZenView(props ZenViewProps) *ZenView {
+	v := &ZenView{ZenViewProps: props}
+	v.initPrimitive()
+	return v
+}
+
+func (v *ZenView) initPrimitive() {
+	v.Primitive = tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(v.editor.Primitive, v.width, 0, true)
+
+	// the parent goal is right next to the centered goal
+	if v.parent != nil {
+		sidebar := tview.NewTextView()
+		sidebar.SetDynamicColors(true)
+		sidebar.SetWordWrap(true)
+		sidebar.SetTextColor(v.theme.PastText)
+		sidebar.SetText(renderMarkdown(v.parent.Content))
+		sidebar.SetBorder(true).
+			SetBorderColor(v.theme.Border).
+			SetTitle(fmt.Sprintf("%s %s", model.PeriodName(v.parent.Period), v.parent.FormatStart())).
+			SetTitleColor(v.theme.PastTitle)
+
+		v.Primitive.AddItem(sidebar, zenSidebarWidth, 0, false)
+	}
+
+	v.Primitive.AddItem(nil, 0, 1, false)
+}
@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"context"
+	"github.com/nvbn/termonizer/internal/model"
+	"github.com/rivo/tview"
+	"testing"
+	"time"
+)
+
+type goalsRepositoryMock struct {
+	goals []model.Goal
+}
+
+func (m *goalsRepositoryMock) FindForPeriod(ctx context.Context, period model.Period) ([]model.Goal, error) {
+	found := make([]model.Goal, 0)
+	for _, goal := range m.goals {
+		if goal.Period == period {
+			found = append(found, goal)
+		}
+	}
+	return found, nil
+}
+
+func (m *goalsRepositoryMock) CountForPeriod(ctx context.Context, period model.Period) (int, error) {
+	found, _ := m.FindForPeriod(ctx, period)
+	return len(found), nil
+}
+
+func (m *goalsRepositoryMock) Update(ctx context.Context, goal model.Goal) error {
+	for n, existing := range m.goals {
+		if existing.ID == goal.ID {
+			m.goals[n] = goal
+			return nil
+		}
+	}
+
+	m.goals = append(m.goals, goal)
+	return nil
+}
+
+func (m *goalsRepositoryMock) UpdateMany(ctx context.Context, goals []model.Goal) error {
+	for _, goal := range goals {
+		if err := m.Update(ctx, goal); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *goalsRepositoryMock) Delete(ctx context.Context, goal model.Goal) error {
+	return nil
+}
+
+func (m *goalsRepositoryMock) Revisions(ctx context.Context, goalID string) ([]model.Revision, error) {
+	return nil, nil
+}
+
+func (m *goalsRepositoryMock) Search(ctx context.Context, query string) ([]model.Goal, error) {
+	return nil, nil
+}
+
+func (m *goalsRepositoryMock) All(ctx context.Context) ([]model.Goal, error) {
+	return m.goals, nil
+}
+
+func TestParentGoal(t *testing.T) {
+	dt := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+
+	year := model.NewGoalForYear(dt)
+	year.ID = "year"
+	previousQuarter := model.NewGoalForQuarter(dt.AddDate(0, -3, 0))
+	previousQuarter.ID = "previous-quarter"
+	quarter := model.NewGoalForQuarter(dt)
+	quarter.ID = "quarter"
+	week := model.NewGoalForWeek(dt)
+	week.ID = "week"
+	day := model.NewGoalForDay(dt)
+	day.ID = "day"
+
+	repository := &goalsRepositoryMock{goals: []model.Goal{year, quarter, previousQuarter, week}}
+
+	inputsExpecteds := []struct {
+		goal     model.Goal
+		expected string
+	}{
+		{day, "week"},
+		{week, "quarter"},
+		{quarter, "year"},
+		{year, ""},
+		// no stored week
+		{model.NewGoalForDay(dt.AddDate(0, 0, 14)), ""},
+	}
+
+	for _, tt := range inputsExpecteds {
+		parent, ok := parentGoal(t.Context(), repository, tt.goal)
+		if ok != (tt.expected != "") {
+			t.Errorf("%s: expected %q, got %v", tt.goal.ID, tt.expected, ok)
+		} else if parent.ID != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.goal.ID, tt.expected, parent.ID)
+		}
+	}
+}
+
+func TestZenView_Sidebar(t *testing.T) {
+	editor := &GoalEditor{Primitive: tview.NewFlex()}
+	v := NewZenView(ZenViewProps{editor: editor, parent: &model.Goal{Period: model.Week, Content: "* plan"}, width: 100})
+
+	if v.Primitive.GetItemCount() != 4 || v.Primitive.GetItem(1) != editor.Primitive {
+		t.Fatalf("expected spacers around the goal and the sidebar, got %d items", v.Primitive.GetItemCount())
+	}
+
+	if _, ok := v.Primitive.GetItem(2).(*tview.TextView); !ok {
+		t.Errorf("expected the sidebar right after the goal, got %T", v.Primitive.GetItem(2))
+	}
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ConfigPath returns a path inside the app directory in XDG_CONFIG_HOME
+func ConfigPath(name string) string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = "."
+		}
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, "termonizer", name)
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestConfigPath(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/tmp/config")
+
+	expected := "/tmp/config/termonizer/themes.toml"
+	if actual := ConfigPath("themes.toml"); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", "/tmp/home")
+
+	expected = "/tmp/home/.config/termonizer/themes.toml"
+	if actual := ConfigPath("themes.toml"); actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+}
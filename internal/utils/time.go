@@ -6,14 +6,30 @@ func QuarterFromTime(t time.Time) int {
 	return (int(t.Month())-1)/3 + 1
 }
 
+var weekStartDay = time.Monday
+
+// SetWeekStart changes the first day of the week, should be called before creating goals
+func SetWeekStart(day time.Weekday) {
+	weekStartDay = day
+}
+
 func WeekStart(t time.Time) time.Time {
-	weekDay := t.Weekday()
-	if weekDay == time.Sunday {
-		weekDay = 7
-	}
-	weekDay -= 1
+	offset := (int(t.Weekday()) - int(weekStartDay) + 7) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+// WeekNumber returns the ISO number of the week containing monday of the week of t
+func WeekNumber(t time.Time) int {
+	start := WeekStart(t)
+	toMonday := (int(time.Monday) - int(start.Weekday()) + 7) % 7
+	_, number := start.AddDate(0, 0, toMonday).ISOWeek()
+	return number
+}
 
-	return t.AddDate(0, 0, -int(weekDay))
+// CompareDates compares only dates ignoring time
+func CompareDates(a time.Time, b time.Time) int {
+	return time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC).Compare(
+		time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC))
 }
 
 func IgnoreTZ(t time.Time) time.Time {
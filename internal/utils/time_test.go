@@ -36,3 +36,46 @@ func TestIgnoreTZ(t *testing.T) {
 	}
 
 }
+
+func TestWeekStart(t *testing.T) {
+	defer SetWeekStart(time.Monday)
+
+	tuesday := time.Date(2024, 12, 10, 0, 0, 0, 0, time.Local)
+
+	if actual := WeekStart(tuesday); actual.Format("2006-01-02") != "2024-12-09" {
+		t.Errorf("expected week to start on monday 2024-12-09, got %v", actual)
+	}
+
+	SetWeekStart(time.Sunday)
+
+	if actual := WeekStart(tuesday); actual.Format("2006-01-02") != "2024-12-08" {
+		t.Errorf("expected week to start on sunday 2024-12-08, got %v", actual)
+	}
+
+	sunday := time.Date(2024, 12, 8, 0, 0, 0, 0, time.Local)
+	if actual := WeekStart(sunday); !actual.Equal(sunday) {
+		t.Errorf("expected sunday to start the week, got %v", actual)
+	}
+
+	if actual := WeekNumber(sunday); actual != 50 {
+		t.Errorf("expected week 50, got %d", actual)
+	}
+}
+
+func TestCompareDates(t *testing.T) {
+	morning := time.Date(2024, 12, 10, 8, 0, 0, 0, time.Local)
+	evening := time.Date(2024, 12, 10, 20, 0, 0, 0, time.Local)
+	nextDay := time.Date(2024, 12, 11, 1, 0, 0, 0, time.Local)
+
+	if CompareDates(morning, evening) != 0 {
+		t.Errorf("expected the same date")
+	}
+
+	if CompareDates(evening, nextDay) != -1 {
+		t.Errorf("expected the date to be before")
+	}
+
+	if CompareDates(nextDay, morning) != 1 {
+		t.Errorf("expected the date to be after")
+	}
+}